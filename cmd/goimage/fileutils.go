@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/nirdeo/goimage/pkg/project"
 )
 
 // Les informations d'un dossier ou fichier
@@ -51,8 +53,8 @@ func listDirectory(dirPath string) ([]FileInfo, error) {
 			ModTime: info.ModTime().Format("2006-01-02 15:04"),
 		}
 
-		// Vérif Image
-		if !entry.IsDir() && !isImageFile(entry.Name()) {
+		// Vérif Image ou projet
+		if !entry.IsDir() && !isImageFile(entry.Name()) && !isProjectFile(entry.Name()) {
 			continue
 		}
 
@@ -76,7 +78,18 @@ func listDirectory(dirPath string) ([]FileInfo, error) {
 // extension supportée
 func isImageFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
-	return ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif"
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".tif", ".tiff", ".qoi", ".ico", ".cur", ".tga":
+		return true
+	case ".pbm", ".pgm", ".ppm", ".pnm", ".pam":
+		return true
+	}
+	return false
+}
+
+// projet GoImage
+func isProjectFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), project.Extension)
 }
 
 // Affichage UX
@@ -84,8 +97,8 @@ func displayFileList(files []FileInfo, currentDir string) {
 	clearScreen()
 	
 	drawBox("Navigation de fichiers", []string{
-		"Répertoire actuel: " + currentDir,
-		"Fichiers et dossiers disponibles (images uniquement):",
+		"Répertoire actuel: " + ellipsizeMiddle(currentDir, 48),
+		"Fichiers et dossiers disponibles (images et projets uniquement):",
 		"",
 		"Navigation: tapez le nom ou le numéro",
 		".. = répertoire parent | [Enter] = répertoire actuel",
@@ -105,15 +118,19 @@ func displayFileList(files []FileInfo, currentDir string) {
 		if file.IsDir {
 			icon = ColorBlue + "📁" + ColorReset
 			sizeStr = "<DIR>"
+		} else if isProjectFile(file.Name) {
+			icon = ColorPurple + IconProject + ColorReset
+			sizeStr = formatFileSize(file.Size)
 		} else {
 			icon = ColorGreen + "🖼️" + ColorReset
 			sizeStr = formatFileSize(file.Size)
 		}
 		
-		fmt.Printf("%s [%d] %-30s %s %10s %s\n",
+		// Les noms longs sont raccourcis par le milieu pour garder l'extension
+		fmt.Printf("%s [%d] %s %s %10s %s\n",
 			icon,
 			i+1,
-			file.Name,
+			padWidth(ellipsizeMiddle(file.Name, 30), 30),
 			ColorCyan+file.ModTime+ColorReset,
 			sizeStr,
 			ColorReset,
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+)
+
+// readPaint interprète une saisie de couleur: R,G,B pour une couleur unie,
+// "d" pour composer un dégradé
+func readPaint(input string, bounds image.Rectangle) (color.Color, bool) {
+	if strings.EqualFold(strings.TrimSpace(input), "d") {
+		return askGradient(bounds)
+	}
+	c, ok := parseRGB(input)
+	if !ok {
+		return nil, false
+	}
+	return c, true
+}
+
+// askGradient compose un dégradé linéaire, radial ou conique; les
+// coordonnées sont celles de l'image
+func askGradient(bounds image.Rectangle) (color.Color, bool) {
+	fmt.Println("🌈 Types de dégradé:")
+	fmt.Println("  1. Linéaire (d'un point à un autre)")
+	fmt.Println("  2. Radial (du centre vers le bord)")
+	fmt.Println("  3. Conique (autour du centre)")
+	kind := readUserInput("Type de dégradé (Entrée = linéaire)")
+
+	fmt.Println("💡 Couleurs séparées par ; avec position facultative en % après @")
+	fmt.Println("  • 255,0,0;0,0,255 = du rouge au bleu")
+	fmt.Println("  • 255,255,255;255,255,255@40;0,0,0 = vignettage (blanc puis noir)")
+	stops, ok := parseStops(readUserInput("Couleurs du dégradé"))
+	if !ok {
+		return nil, false
+	}
+	base := effects.Gradient{Stops: stops}
+
+	switch kind {
+	case "2":
+		fmt.Printf("💡 Centre de l'image: %d,%d\n", bounds.Min.X+bounds.Dx()/2, bounds.Min.Y+bounds.Dy()/2)
+		c, ok := readPoints(1)
+		if !ok {
+			return nil, false
+		}
+		rx, err := strconv.ParseFloat(strings.TrimSpace(readUserInput("Rayon horizontal en pixels")), 64)
+		if err != nil || rx <= 0 {
+			return nil, false
+		}
+		ry := rx
+		if input := readUserInput("Rayon vertical (Entrée = identique)"); input != "" {
+			ry, err = strconv.ParseFloat(strings.TrimSpace(input), 64)
+			if err != nil || ry <= 0 {
+				return nil, false
+			}
+		}
+		base.Spread = askSpread()
+		return &effects.RadialGradient{
+			Gradient: base,
+			CX:       float64(c[0].X) + 0.5,
+			CY:       float64(c[0].Y) + 0.5,
+			RX:       rx,
+			RY:       ry,
+		}, true
+	case "3":
+		fmt.Println("💡 Centre au format X,Y")
+		c, ok := readPoints(1)
+		if !ok {
+			return nil, false
+		}
+		angle := 0.0
+		if input := readUserInput("Angle de départ en degrés, sens horaire depuis la droite (Entrée = 0)"); input != "" {
+			v, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+			if err != nil {
+				return nil, false
+			}
+			angle = v
+		}
+		return &effects.ConicGradient{
+			Gradient: base,
+			CX:       float64(c[0].X) + 0.5,
+			CY:       float64(c[0].Y) + 0.5,
+			Angle:    angle,
+		}, true
+	}
+
+	fmt.Printf("💡 Ciel vertical: 0,0 puis 0,%d\n", bounds.Dy()-1)
+	fmt.Println("Point de départ puis point d'arrivée au format X,Y")
+	p, ok := readPoints(2)
+	if !ok {
+		return nil, false
+	}
+	base.Spread = askSpread()
+	return &effects.LinearGradient{
+		Gradient: base,
+		X0:       float64(p[0].X) + 0.5,
+		Y0:       float64(p[0].Y) + 0.5,
+		X1:       float64(p[1].X) + 0.5,
+		Y1:       float64(p[1].Y) + 0.5,
+	}, true
+}
+
+// askSpread demande comment prolonger le dégradé au-delà de ses bornes
+func askSpread() effects.Spread {
+	switch readUserInput("Au-delà des bornes: 1 étendre, 2 répéter, 3 refléter (Entrée = étendre)") {
+	case "2":
+		return effects.Repeat
+	case "3":
+		return effects.Reflect
+	}
+	return effects.Pad
+}
+
+// parseStops lit des couleurs R,G,B[@position%] séparées par des
+// points-virgules; sans position, les couleurs sont réparties régulièrement
+// entre leurs voisines
+func parseStops(s string) ([]effects.ColorStop, bool) {
+	parts := strings.Split(s, ";")
+	if len(parts) < 2 {
+		return nil, false
+	}
+	stops := make([]effects.ColorStop, len(parts))
+	set := make([]bool, len(parts))
+	for i, part := range parts {
+		rgb, pos, hasPos := strings.Cut(part, "@")
+		c, ok := parseRGB(rgb)
+		if !ok {
+			return nil, false
+		}
+		stops[i].Color = c
+		if hasPos {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pos), "%"), 64)
+			if err != nil || v < 0 || v > 100 {
+				return nil, false
+			}
+			stops[i].Offset, set[i] = v/100, true
+		}
+	}
+	if !set[0] {
+		stops[0].Offset, set[0] = 0, true
+	}
+	if last := len(stops) - 1; !set[last] {
+		stops[last].Offset, set[last] = 1, true
+	}
+
+	// Répartition des positions manquantes, puis contrôle de l'ordre
+	for i := 1; i < len(stops); i++ {
+		if set[i] {
+			continue
+		}
+		j := i
+		for !set[j] {
+			j++
+		}
+		from, to := stops[i-1].Offset, stops[j].Offset
+		for k := i; k < j; k++ {
+			stops[k].Offset = from + (to-from)*float64(k-i+1)/float64(j-i+1)
+			set[k] = true
+		}
+	}
+	for i := 1; i < len(stops); i++ {
+		if stops[i].Offset < stops[i-1].Offset {
+			return nil, false
+		}
+	}
+	return stops, true
+}
+
+// paintLabel décrit une couleur unie ou un dégradé pour l'affichage
+func paintLabel(c color.Color) string {
+	switch g := c.(type) {
+	case *effects.LinearGradient:
+		return fmt.Sprintf("dégradé linéaire (%d couleurs)", len(g.Stops))
+	case *effects.RadialGradient:
+		return fmt.Sprintf("dégradé radial (%d couleurs)", len(g.Stops))
+	case *effects.ConicGradient:
+		return fmt.Sprintf("dégradé conique (%d couleurs)", len(g.Stops))
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("RGB(%d,%d,%d)", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"unicode/utf8"
+)
+
+// terminalResized est levé à chaque SIGWINCH, jusqu'au prochain dessin
+var terminalResized atomic.Bool
+
+type keyKind int
+
+const (
+	// keyNone: aucune touche pendant le délai de lecture
+	keyNone keyKind = iota
+	keyRune
+	keyEnter
+	keyEscape
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyBackspace
+	keyCtrl
+	keyOther
+)
+
+// key est une touche lue en mode brut; r porte le caractère pour keyRune et
+// la lettre (majuscule) pour keyCtrl
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// readKey lit une touche en mode brut. Les séquences des flèches arrivent
+// en un seul bloc; un Échap isolé est la touche Échap
+func readKey() (key, error) {
+	buf := make([]byte, 16)
+	n, err := os.Stdin.Read(buf)
+	if n == 0 && (err == nil || err == io.EOF) {
+		return key{kind: keyNone}, nil
+	}
+	if err != nil {
+		return key{}, err
+	}
+	b := buf[:n]
+	switch {
+	case n == 1 && b[0] == 0x1b:
+		return key{kind: keyEscape}, nil
+	case b[0] == 0x1b:
+		switch strings.TrimLeft(string(b[1:]), "[O") {
+		case "A":
+			return key{kind: keyUp}, nil
+		case "B":
+			return key{kind: keyDown}, nil
+		case "C":
+			return key{kind: keyRight}, nil
+		case "D":
+			return key{kind: keyLeft}, nil
+		case "H", "1~", "7~":
+			return key{kind: keyHome}, nil
+		case "F", "4~", "8~":
+			return key{kind: keyEnd}, nil
+		}
+		return key{kind: keyOther}, nil
+	case b[0] == '\r' || b[0] == '\n':
+		return key{kind: keyEnter}, nil
+	case b[0] == 0x7f || b[0] == 0x08:
+		// Selon le terminal, Retour arrière envoie DEL ou Ctrl+H
+		return key{kind: keyBackspace}, nil
+	case b[0] < 0x20:
+		return key{kind: keyCtrl, r: rune(b[0]) + '@'}, nil
+	}
+	r, _ := utf8.DecodeRune(b)
+	return key{kind: keyRune, r: r}, nil
+}
+
+// ctrlShortcut renvoie la lettre d'un raccourci "Ctrl+X", 0 sinon
+func ctrlShortcut(s string) rune {
+	if rest, ok := strings.CutPrefix(s, "Ctrl+"); ok && len(rest) == 1 {
+		return rune(strings.ToUpper(rest)[0])
+	}
+	return 0
+}
+
+// handleTerminalSignals rétablit le terminal si le programme est interrompu
+// pendant le mode brut, et note les redimensionnements
+func handleTerminalSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ch
+		restoreTerminal()
+		fmt.Println(ShowCursor + ColorReset)
+		os.Exit(130)
+	}()
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+	go func() {
+		for range resize {
+			terminalResized.Store(true)
+		}
+	}()
+}
+
+// selectMenu fait choisir une entrée d'un menu de count éléments et renvoie
+// son numéro ("1", "2"...) ou l'une des lettres de valid. draw efface
+// l'écran et dessine le menu avec l'entrée selected en surbrillance.
+//
+// Dans un terminal, les flèches déplacent la sélection, Entrée la valide,
+// un numéro ou un raccourci Ctrl+X choisit directement, Retour arrière
+// efface le dernier chiffre saisi et Échap renvoie back (ignoré si back est
+// vide). L'écran est redessiné quand le terminal
+// change de taille. Hors terminal, le choix est saisi au clavier.
+func selectMenu(draw func(selected int), count int, shortcuts []string, message string, valid []string, back string) string {
+	if !isTerminal(os.Stdin) || enableRawMode() != nil {
+		draw(-1)
+		return promptWithValidation(message, valid)
+	}
+	defer restoreTerminal()
+	fmt.Print(HideCursor)
+	defer fmt.Print(ShowCursor)
+
+	isValid := func(s string) bool {
+		for _, v := range valid {
+			if strings.EqualFold(v, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	selected := 0
+	typed := ""
+	redraw := true
+	for {
+		if redraw || terminalResized.Swap(false) {
+			draw(selected)
+			cols, _ := terminalSize()
+			fmt.Println(ColorDim + truncateWidth("↑/↓ naviguer • Entrée valider • numéro ou Ctrl+lettre = accès direct • Échap retour", cols-1) + ColorReset)
+			fmt.Print(ColorYellow + IconQuestion + " " + message + ColorReset + " " + typed)
+		}
+
+		k, err := readKey()
+		if err != nil {
+			return back
+		}
+		redraw = k.kind != keyNone
+		switch k.kind {
+		case keyUp:
+			selected = (selected + count - 1) % count
+			typed = ""
+		case keyDown:
+			selected = (selected + 1) % count
+			typed = ""
+		case keyHome:
+			selected, typed = 0, ""
+		case keyEnd:
+			selected, typed = count-1, ""
+		case keyBackspace:
+			if typed != "" {
+				typed = typed[:len(typed)-1]
+			}
+			if typed != "" {
+				n, _ := strconv.Atoi(typed)
+				selected = n - 1
+			}
+		case keyEnter:
+			fmt.Println()
+			return strconv.Itoa(selected + 1)
+		case keyEscape:
+			if back != "" {
+				fmt.Println()
+				return back
+			}
+		case keyCtrl:
+			for i, s := range shortcuts {
+				if i < count && ctrlShortcut(s) == k.r {
+					fmt.Println()
+					return strconv.Itoa(i + 1)
+				}
+			}
+		case keyRune:
+			if k.r >= '0' && k.r <= '9' {
+				// Un numéro qui peut encore être prolongé (1 dans un menu
+				// de 12 entrées) attend le chiffre suivant ou Entrée
+				n, _ := strconv.Atoi(typed + string(k.r))
+				if n < 1 || n > count {
+					n, _ = strconv.Atoi(string(k.r))
+				}
+				if n < 1 || n > count {
+					typed = ""
+					continue
+				}
+				selected, typed = n-1, strconv.Itoa(n)
+				if n*10 > count {
+					fmt.Println()
+					return typed
+				}
+				continue
+			}
+			if isValid(string(k.r)) {
+				fmt.Println()
+				return strings.ToLower(string(k.r))
+			}
+		}
+	}
+}
+
+// waitKey attend une touche en mode brut; keyNone signale que le terminal a
+// changé de taille. ok est faux hors terminal
+func waitKey() (k key, ok bool) {
+	if !isTerminal(os.Stdin) || enableRawMode() != nil {
+		return key{}, false
+	}
+	defer restoreTerminal()
+	for {
+		k, err := readKey()
+		if err != nil {
+			return key{kind: keyEscape}, true
+		}
+		if k.kind != keyNone || terminalResized.Swap(false) {
+			return k, true
+		}
+	}
+}
+
+// readConfirmKey lit une réponse o/n en une touche; Échap et Entrée valent
+// non. Hors terminal, la réponse est saisie au clavier
+func readConfirmKey() (bool, bool) {
+	if !isTerminal(os.Stdin) || enableRawMode() != nil {
+		return false, false
+	}
+	defer restoreTerminal()
+	for {
+		k, err := readKey()
+		if err != nil {
+			return false, true
+		}
+		switch {
+		case k.kind == keyRune && (k.r == 'o' || k.r == 'O'):
+			fmt.Println("oui")
+			return true, true
+		case k.kind == keyRune && (k.r == 'n' || k.r == 'N'), k.kind == keyEnter, k.kind == keyEscape:
+			fmt.Println("non")
+			return false, true
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+	"github.com/nirdeo/goimage/pkg/layers"
+)
+
+// layerLines décrit la pile de calques, du dessus vers le fond
+func layerLines(doc *layers.Document) []string {
+	lines := []string{
+		fmt.Sprintf("🖼️ Document: %d × %d pixels, %d calque(s)", doc.Width, doc.Height, len(doc.Layers)),
+		"",
+	}
+	for i := len(doc.Layers) - 1; i >= 0; i-- {
+		l := doc.Layers[i]
+		marker := "  "
+		if i == doc.Active {
+			marker = "▶ "
+		}
+		visible := "👁️"
+		if !l.Visible {
+			visible = "🚫"
+		}
+		line := fmt.Sprintf("%s%2d. %s %s %3.0f%%  %-20s (%d,%d)",
+			marker, i+1, visible, padWidth(truncateWidth(l.Name, 20), 20), l.Opacity*100, l.Mode, l.Offset.X, l.Offset.Y)
+		if l.Operator != effects.SrcOver {
+			line += " " + l.Operator.String()
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "💡 ▶ = calque actif, modifié par les effets")
+	return lines
+}
+
+// manageLayers affiche et modifie la pile de calques du document; record
+// note chaque modification dans l'historique
+func manageLayers(doc *layers.Document, record func(detail, layer string)) {
+	items := []string{
+		"Choisir le calque actif",
+		"Nouveau calque vide",
+		"Importer une image en calque",
+		"Dupliquer le calque actif",
+		"Propriétés (nom, opacité, mode, décalage)",
+		"Afficher / masquer le calque actif",
+		"Monter le calque actif",
+		"Descendre le calque actif",
+		"Supprimer le calque actif",
+		"Aplatir l'image (fusionner tous les calques)",
+		"Aperçu du rendu",
+		"Aide",
+		"Retour",
+	}
+	icons := []string{"▶️", "➕", "📥", "📑", "⚙️", "👁️", "⬆️", "⬇️", "🗑️", "🧱", "🔍", IconHelp, "↩️"}
+
+	for {
+		choice := selectMenu(func(selected int) {
+			clearScreen()
+			drawBox("Calques", layerLines(doc), 80)
+			fmt.Println()
+			drawMenu("Actions", items, icons, []string{}, selected, 70)
+		}, len(items), nil, "Choisissez une action", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "h"}, "13")
+		active := doc.ActiveLayer()
+		switch choice {
+		case "1":
+			n, err := strconv.Atoi(readUserInput(fmt.Sprintf("Numéro du calque (1 à %d)", len(doc.Layers))))
+			if err != nil || n < 1 || n > len(doc.Layers) {
+				errorMessage("Calque inexistant")
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			doc.Active = n - 1
+		case "2":
+			name := readUserInput("Nom du calque (Entrée = automatique)")
+			if name == "" {
+				name = fmt.Sprintf("Calque %d", len(doc.Layers)+1)
+			}
+			doc.Insert(doc.NewLayer(name))
+			record("Nouveau calque", name)
+		case "3":
+			infoMessage("Sélectionnez l'image à importer")
+			path, err := navigateToFile()
+			if err != nil {
+				warningMessage("Import annulé")
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			src, err := decodeImageFile(path)
+			if err != nil {
+				errorMessage(err.Error())
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			layer := &layers.Layer{Name: filepath.Base(path), Image: src, Opacity: 1, Visible: true}
+			sb := src.Bounds()
+			fmt.Printf("🖼️ Image importée: %d × %d pixels\n", sb.Dx(), sb.Dy())
+			fmt.Println("💡 Position du coin supérieur gauche au format X,Y")
+			if pos, ok := readPoints(1); ok {
+				layer.Offset = pos[0]
+			} else {
+				warningMessage("Position invalide, utilisation de 0,0")
+			}
+			doc.Insert(layer)
+			record(fmt.Sprintf("Import en %d,%d", layer.Offset.X, layer.Offset.Y), layer.Name)
+		case "4":
+			doc.Duplicate()
+			record("Duplication de "+active.Name, doc.ActiveLayer().Name)
+		case "5":
+			before := *active
+			editLayerProperties(active)
+			if *active != before {
+				record(fmt.Sprintf("Propriétés: opacité %.0f %%, %s, %s, décalage %d,%d",
+					active.Opacity*100, active.Mode, active.Operator, active.Offset.X, active.Offset.Y), active.Name)
+			}
+		case "6":
+			active.Visible = !active.Visible
+			if active.Visible {
+				record("Affiché", active.Name)
+			} else {
+				record("Masqué", active.Name)
+			}
+		case "7", "8":
+			delta := 1
+			if choice == "8" {
+				delta = -1
+			}
+			if !doc.Move(doc.Active, delta) {
+				warningMessage("Le calque est déjà à l'extrémité de la pile")
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			record(fmt.Sprintf("Déplacé en position %d", doc.Active+1), active.Name)
+		case "9":
+			if !confirmAction(fmt.Sprintf("Supprimer le calque « %s » ?", active.Name)) {
+				continue
+			}
+			if err := doc.Remove(doc.Active); err != nil {
+				errorMessage(err.Error())
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			record("Suppression", active.Name)
+		case "10":
+			if confirmAction("Fusionner tous les calques visibles en un seul ?") {
+				doc.Merge()
+				record("Aplatissement", "")
+				successMessage("Image aplatie")
+				time.Sleep(1 * time.Second)
+			}
+		case "11":
+			clearScreen()
+			showPreview(doc.Flatten(), "Rendu des calques")
+			readUserInput("Appuyez sur Entrée pour continuer")
+		case "12", "h":
+			showHelp("layers")
+		default:
+			return
+		}
+	}
+}
+
+// editLayerProperties modifie nom, opacité, mode de fusion, opérateur et
+// décalage; Entrée conserve la valeur actuelle
+func editLayerProperties(l *layers.Layer) {
+	if name := readUserInput(fmt.Sprintf("Nom (Entrée = %s)", l.Name)); name != "" {
+		l.Name = name
+	}
+	if input := readUserInput(fmt.Sprintf("Opacité en %% (Entrée = %.0f)", l.Opacity*100)); input != "" {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(input), "%"), 64)
+		if err != nil || v < 0 || v > 100 {
+			warningMessage("Opacité invalide, valeur conservée")
+		} else {
+			l.Opacity = v / 100
+		}
+	}
+
+	fmt.Println("🎨 Modes de fusion:")
+	for m := effects.Normal; m <= effects.Difference; m++ {
+		fmt.Printf("  %d. %s\n", int(m)+1, m)
+	}
+	if v, err := strconv.Atoi(readUserInput(fmt.Sprintf("Mode de fusion (Entrée = %s)", l.Mode))); err == nil && v >= 1 && v <= int(effects.Difference)+1 {
+		l.Mode = effects.BlendMode(v - 1)
+	}
+
+	fmt.Println("🧩 Opérateurs de Porter-Duff:")
+	for o := effects.SrcOver; o <= effects.Clear; o++ {
+		fmt.Printf("  %d. %s\n", int(o)+1, o)
+	}
+	if v, err := strconv.Atoi(readUserInput(fmt.Sprintf("Opérateur (Entrée = %s)", l.Operator))); err == nil && v >= 1 && v <= int(effects.Clear)+1 {
+		l.Operator = effects.Operator(v - 1)
+	}
+
+	if input := readUserInput(fmt.Sprintf("Décalage X,Y (Entrée = %d,%d)", l.Offset.X, l.Offset.Y)); input != "" {
+		parts := strings.Split(input, ",")
+		x, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+		var y int
+		var err2 error
+		if len(parts) == 2 {
+			y, err2 = strconv.Atoi(strings.TrimSpace(parts[1]))
+		}
+		if len(parts) != 2 || err1 != nil || err2 != nil {
+			warningMessage("Décalage invalide, valeur conservée")
+			time.Sleep(1 * time.Second)
+		} else {
+			l.Offset = image.Point{x, y}
+		}
+	}
+}
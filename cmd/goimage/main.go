@@ -2,12 +2,15 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -21,20 +24,74 @@ import (
 	
 	// Import des effets depuis le package
 	"github.com/nirdeo/goimage/pkg/effects"
+	"github.com/nirdeo/goimage/pkg/exif"
+
+	// Formats supplémentaires (enregistrés auprès du package image)
+	"github.com/nirdeo/goimage/pkg/icc"
+	"github.com/nirdeo/goimage/pkg/ico"
+	"github.com/nirdeo/goimage/pkg/layers"
+	"github.com/nirdeo/goimage/pkg/metadata"
+	"github.com/nirdeo/goimage/pkg/netpbm"
+	"github.com/nirdeo/goimage/pkg/project"
+	"github.com/nirdeo/goimage/pkg/qoi"
+	"github.com/nirdeo/goimage/pkg/tga"
+	"github.com/nirdeo/goimage/pkg/tiff"
+	"github.com/nirdeo/goimage/pkg/truetype"
 )
 
 // Variable globale pour déterminer si c'est la première utilisation
 var isFirstTime = true
 
+// Redressement automatique des photos selon leur orientation EXIF
+var autoOrient = true
+
+// Métadonnées de l'image chargée, conservées jusqu'à la sauvegarde
+var currentMetadata *metadata.Metadata
+
 func main() {
+	// Sous-commande de traitement par lot, sans interface
+	if len(os.Args) > 1 && os.Args[1] == "watermark" {
+		os.Exit(runWatermarkCommand(os.Args[2:]))
+	}
+
+	noOrient := flag.Bool("no-auto-orient", false, "ne pas redresser les photos selon leur orientation EXIF")
+	preview := flag.String("preview", "", "mode d'aperçu: auto, kitty, sixel, truecolor, 256 ou ascii")
+	flag.Parse()
+	autoOrient = !*noOrient
+
+	// Le réglage en ligne de commande prime sur la variable d'environnement
+	if env := os.Getenv("GOIMAGE_PREVIEW"); env != "" {
+		previewSetting = env
+	}
+	if *preview != "" {
+		previewSetting = *preview
+	}
+
+	// Le terminal est rétabli même en cas d'interruption ou de panique
+	handleTerminalSignals()
+	defer func() {
+		if r := recover(); r != nil {
+			restoreTerminal()
+			fmt.Print(ShowCursor)
+			panic(r)
+		}
+	}()
+
 	StartTUI()
 }
 
 func StartTUI() {
-	var img image.Image
+	// Le document et ses calques; nil tant qu'aucune image n'est chargée
+	var doc *layers.Document
 	var err error
 	var currentFilePath string
 	var imageFormat string
+	// Image telle que chargée et historique, conservés dans les projets
+	var original image.Image
+	var history []project.Edit
+	record := func(action, detail, layer string) {
+		history = append(history, project.Edit{Action: action, Detail: detail, Layer: layer, Time: time.Now()})
+	}
 
 	if isFirstTime {
 		showWelcomeBanner()
@@ -47,6 +104,9 @@ func StartTUI() {
 		"Dessiner une forme",
 		"Convertir l'image",
 		"Sauvegarder l'image",
+		"Gérer les calques",
+		"Ouvrir un projet",
+		"Sauvegarder le projet",
 		"Quitter",
 	}
 
@@ -56,6 +116,9 @@ func StartTUI() {
 		IconShape,
 		IconConvert,
 		IconSave,
+		IconLayers,
+		IconOpen,
+		IconProject,
 		IconExit,
 	}
 
@@ -65,18 +128,19 @@ func StartTUI() {
 		"Ctrl+D",
 		"Ctrl+C",
 		"Ctrl+S",
+		"Ctrl+L",
+		"Ctrl+P",
+		"Ctrl+K",
 		"Ctrl+Q",
 	}
 
 	for {
-		drawHeader()
-		
-		drawStatusBar(currentFilePath, img != nil)
-		
-		drawMenu("Menu Principal", menuItems, menuIcons, menuShortcuts, -1, 70)
-		drawFooter()
-
-		choice := promptWithValidation("Choisissez une option", []string{"1", "2", "3", "4", "5", "6", "h", "q"})
+		choice := selectMenu(func(selected int) {
+			drawHeader()
+			drawStatusBar(currentFilePath, doc != nil)
+			drawMenu("Menu Principal", menuItems, menuIcons, menuShortcuts, selected, 70)
+			drawFooter()
+		}, len(menuItems), menuShortcuts, "Choisissez une option", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "h", "q"}, "")
 
 		if choice == "h" || choice == "H" {
 			showHelp("main")
@@ -126,22 +190,36 @@ func StartTUI() {
 				continue
 			}
 			
+			if isProjectFile(filePath) {
+				errorMessageWithTip("Ce fichier est un projet GoImage, pas une image", "Utilisez l'option 7 pour ouvrir un projet")
+				time.Sleep(2 * time.Second)
+				continue
+			}
+
+			var img image.Image
 			img, currentFilePath, imageFormat, err = loadImageFromPathEnhanced(filePath)
 			if err != nil {
-				errorMessageWithTip(fmt.Sprintf("Erreur lors du chargement: %v", err), "Vérifiez que le fichier est une image valide (PNG, JPEG, GIF)")
+				errorMessageWithTip(fmt.Sprintf("Erreur lors du chargement: %v", err), "Vérifiez que le fichier est une image valide (PNG, JPEG, GIF, Netpbm, TIFF, QOI, ICO, TGA)")
 				time.Sleep(2 * time.Second)
 			} else if img == nil {
 				errorMessage("L'image n'a pas pu être chargée correctement")
 				time.Sleep(2 * time.Second)
 			} else {
+				doc = layers.New(img)
+				original = img
+				history = nil
 				bounds := img.Bounds()
 				successMessage(fmt.Sprintf("Image chargée avec succès!"))
 				displayImageInfo(bounds.Dx(), bounds.Dy(), imageFormat)
-				time.Sleep(2 * time.Second)
+				time.Sleep(1 * time.Second)
+
+				clearScreen()
+				showPreview(img, filepath.Base(currentFilePath))
+				readUserInput("Appuyez sur Entrée pour continuer")
 			}
 			
 		case "2":
-			if img == nil {
+			if doc == nil {
 				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
 				time.Sleep(2 * time.Second)
 				continue
@@ -152,10 +230,15 @@ func StartTUI() {
 				continue
 			}
 			
-			img = applyEffectEnhanced(img)
+			// Les effets ne modifient que le calque actif
+			layer := doc.ActiveLayer()
+			if out, label := applyEffectEnhanced(doc); out != layer.Image {
+				layer.Image = out
+				record("Effet", label, layer.Name)
+			}
 			
 		case "3":
-			if img == nil {
+			if doc == nil {
 				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
 				time.Sleep(2 * time.Second)
 				continue
@@ -166,40 +249,102 @@ func StartTUI() {
 				continue
 			}
 			
-			img = drawShapeEnhanced(img)
+			// Chaque forme est dessinée sur son propre calque, ajouté
+			// au-dessus du calque actif
+			layer := doc.NewLayer(fmt.Sprintf("Calque %d", len(doc.Layers)+1))
+			if drawn, kind := drawShapeEnhanced(layer, doc.Flatten()); drawn != layer.Image {
+				layer.Image = drawn
+				doc.Insert(layer)
+				record("Dessin", kind, layer.Name)
+			}
 			
 		case "4":
-			if img == nil {
+			if doc == nil {
 				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
 				time.Sleep(2 * time.Second)
 				continue
 			}
 			
-			modifiedImg, err := convertImageEnhanced(img)
+			// Exports et transformations portent sur l'image aplatie
+			flat := doc.Flatten()
+			modifiedImg, err := convertImageEnhanced(flat)
 			if err != nil {
 				errorMessageWithTip(fmt.Sprintf("Erreur lors de la conversion: %v", err), "Vérifiez le format de sortie et les permissions d'écriture")
 				time.Sleep(2 * time.Second)
-			} else if modifiedImg != nil {
-				// Mettre à jour l'image principale si elle a été modifiée
-				img = modifiedImg
+			} else if modifiedImg != nil && modifiedImg != flat {
+				// Mettre à jour l'image principale si elle a été modifiée;
+				// les calques sont alors fusionnés
+				if len(doc.Layers) > 1 {
+					infoMessage("Les calques ont été fusionnés")
+				}
+				detail := "Couleurs en sRGB"
+				if fb, mb := flat.Bounds(), modifiedImg.Bounds(); fb.Size() != mb.Size() {
+					detail = fmt.Sprintf("Redimensionnement %d × %d → %d × %d", fb.Dx(), fb.Dy(), mb.Dx(), mb.Dy())
+				}
+				doc = layers.New(modifiedImg)
+				record("Conversion", detail, "")
 				successMessage("Image convertie avec succès!")
 				time.Sleep(1 * time.Second)
 			}
 			
 		case "5":
-			if img == nil {
+			if doc == nil {
 				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
 				time.Sleep(2 * time.Second)
 				continue
 			}
-			err := saveImageEnhanced(img)
+			err := saveImageEnhanced(doc.Flatten())
 			if err != nil {
 				errorMessageWithTip(fmt.Sprintf("Erreur lors de la sauvegarde: %v", err), "Vérifiez le chemin et les permissions d'écriture")
 				time.Sleep(2 * time.Second)
 			}
 			
-		case "6", "q", "Q":
-			if img != nil {
+		case "6":
+			if doc == nil {
+				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			manageLayers(doc, func(detail, layer string) {
+				record("Calque", detail, layer)
+			})
+
+		case "7":
+			if doc != nil && !confirmAction("Le travail en cours sera remplacé. Ouvrir un projet quand même ?") {
+				continue
+			}
+			p, err := openProjectEnhanced()
+			if err != nil {
+				if err.Error() != "navigation annulée" {
+					errorMessageWithTip(fmt.Sprintf("Erreur lors de l'ouverture: %v", err), "Vérifiez que le fichier est un projet GoImage (.goimg)")
+				}
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			doc, original, history = p.Document, p.Original, p.History
+			currentFilePath, imageFormat, currentMetadata = p.Source, p.Format, p.Metadata
+
+		case "8":
+			if doc == nil {
+				errorMessageWithTip("Veuillez d'abord charger une image", "Utilisez l'option 1 pour charger une image")
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			err := saveProjectEnhanced(&project.Project{
+				Document: doc,
+				Original: original,
+				Source:   currentFilePath,
+				Format:   imageFormat,
+				Metadata: currentMetadata,
+				History:  history,
+			})
+			if err != nil {
+				errorMessageWithTip(fmt.Sprintf("Erreur lors de la sauvegarde du projet: %v", err), "Vérifiez le chemin et les permissions d'écriture")
+				time.Sleep(2 * time.Second)
+			}
+
+		case "9", "q", "Q":
+			if doc != nil {
 				if confirmAction("Vous avez une image en cours d'édition. Quitter quand même ?") {
 					clearScreen()
 					successMessage("Merci d'avoir utilisé GoImage. À bientôt! 👋")
@@ -212,7 +357,7 @@ func StartTUI() {
 			}
 			
 		default:
-			warningMessage("Option invalide. Utilisez les numéros 1-6, 'h' pour l'aide, ou 'q' pour quitter")
+			warningMessage("Option invalide. Utilisez les numéros 1-9, 'h' pour l'aide, ou 'q' pour quitter")
 			time.Sleep(1 * time.Second)
 		}
 	}
@@ -231,7 +376,7 @@ func loadImageFromPathEnhanced(filePath string) (image.Image, string, string, er
 	clearScreen()
 	drawBox("Chargement de l'image", []string{
 		"Fichier sélectionné: " + filePath,
-		"Formats supportés: PNG, JPEG, GIF",
+		"Formats supportés: PNG, JPEG, GIF, PBM/PGM/PPM/PAM, TIFF, QOI, ICO/CUR, TGA",
 		"",
 		"Vérification du fichier...",
 	}, 80)
@@ -268,10 +413,50 @@ func loadImageFromPathEnhanced(filePath string) (image.Image, string, string, er
 
 	// Tentative de décodage de l'image
 	img, format, err := image.Decode(file)
+	if err == image.ErrFormat && strings.EqualFold(filepath.Ext(filePath), ".tga") {
+		// TGA n'a pas de signature fiable: on se fie à l'extension
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			img, err = tga.Decode(file)
+			format = "tga"
+		}
+	}
 	if err != nil {
 		return nil, "", "", fmt.Errorf("impossible de décoder l'image (format non supporté?): %v", err)
 	}
 
+	// TIFF multi-pages: l'utilisateur choisit la page à charger
+	if format == "tiff" {
+		img, err = selectTIFFPage(file, img)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("impossible de décoder la page TIFF: %v", err)
+		}
+	}
+
+	// ICO/CUR: l'utilisateur choisit la taille embarquée à charger
+	if format == "ico" || format == "cur" {
+		img, err = selectIconEntry(file, img)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("impossible de décoder l'icône: %v", err)
+		}
+	}
+
+	// JPEG/PNG: lecture des métadonnées et redressement de la photo
+	currentMetadata = readFileMetadata(file, format)
+	if currentMetadata != nil && len(currentMetadata.Exif) > 0 {
+		img = applyExifOrientation(currentMetadata, img)
+	}
+
+	// Profil ICC autre que sRGB: conversion proposée dès le chargement
+	if profile := currentProfile(); profile != nil && !profile.IsSRGB() {
+		infoMessage(fmt.Sprintf("Profil de couleurs embarqué: %s", profileName(profile)))
+		if confirmAction("Convertir les couleurs en sRGB maintenant ?") {
+			img = icc.ToSRGB(img, profile)
+			currentMetadata.ICC = icc.SRGB()
+		} else {
+			infoMessage("Conversion disponible plus tard dans le menu Convertir")
+		}
+	}
+
 	drawProgressBarAnimated(1.0, 50, "Chargement terminé")
 	fmt.Println()
 
@@ -282,6 +467,254 @@ func loadImageFromPathEnhanced(filePath string) (image.Image, string, string, er
 	return img, filePath, format, nil
 }
 
+// selectTIFFPage propose de choisir une page quand le fichier TIFF en contient plusieurs
+func selectTIFFPage(file *os.File, firstPage image.Image) (image.Image, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return firstPage, nil
+	}
+	pages, err := tiff.PageCount(file)
+	if err != nil || pages <= 1 {
+		return firstPage, nil
+	}
+
+	fmt.Println()
+	infoMessage(fmt.Sprintf("Ce fichier TIFF contient %d pages", pages))
+	pageStr := readUserInput(fmt.Sprintf("Page à charger (1-%d, Entrée = 1)", pages))
+	if pageStr == "" {
+		return firstPage, nil
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 || page > pages {
+		warningMessage("Page invalide, chargement de la première page")
+		return firstPage, nil
+	}
+	if page == 1 {
+		return firstPage, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return tiff.DecodePage(file, page-1)
+}
+
+// selectIconEntry propose de choisir une des tailles embarquées dans une icône
+func selectIconEntry(file *os.File, largest image.Image) (image.Image, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return largest, nil
+	}
+	entries, err := ico.Entries(file)
+	if err != nil || len(entries) <= 1 {
+		return largest, nil
+	}
+
+	fmt.Println()
+	infoMessage(fmt.Sprintf("Cette icône contient %d images:", len(entries)))
+	for i, e := range entries {
+		storage := "BMP"
+		if e.PNG {
+			storage = "PNG"
+		}
+		fmt.Printf("  [%d] %d × %d (%d bits, %s)\n", i+1, e.Width, e.Height, e.BitCount, storage)
+	}
+	best := ico.Largest(entries)
+	choice := readUserInput(fmt.Sprintf("Image à charger (1-%d, Entrée = %d)", len(entries), best+1))
+	if choice == "" {
+		return largest, nil
+	}
+
+	index, err := strconv.Atoi(choice)
+	if err != nil || index < 1 || index > len(entries) {
+		warningMessage("Choix invalide, chargement de la plus grande image")
+		return largest, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ico.DecodeEntry(file, index-1)
+}
+
+// readFileMetadata extrait les métadonnées des fichiers JPEG et PNG
+func readFileMetadata(file *os.File, format string) *metadata.Metadata {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+	var meta *metadata.Metadata
+	var err error
+	switch format {
+	case "jpeg":
+		meta, err = metadata.ReadJPEG(file)
+	case "png":
+		meta, err = metadata.ReadPNG(file)
+	default:
+		return nil
+	}
+	if err != nil || meta.Empty() {
+		return nil
+	}
+	return meta
+}
+
+// applyExifOrientation redresse l'image selon le bloc EXIF si besoin
+func applyExifOrientation(meta *metadata.Metadata, img image.Image) image.Image {
+	data, err := exif.Parse(meta.Exif)
+	if err != nil || data.Orientation == 1 {
+		return img
+	}
+	if !autoOrient {
+		infoMessage(fmt.Sprintf("Orientation EXIF ignorée: %s", exif.OrientationName(data.Orientation)))
+		return img
+	}
+
+	// L'image est redressée: l'EXIF conservé ne doit plus la faire tourner
+	if fixed, err := exif.SetOrientation(meta.Exif, 1); err == nil {
+		meta.Exif = fixed
+	}
+	infoMessage(fmt.Sprintf("Orientation EXIF appliquée: %s", exif.OrientationName(data.Orientation)))
+	return exif.ApplyOrientation(img, data.Orientation)
+}
+
+// metadataLines met en forme les métadonnées pour l'écran des métadonnées
+func metadataLines(meta *metadata.Metadata) []string {
+	var lines []string
+	if e, err := exif.Parse(meta.Exif); err == nil {
+		lines = append(lines, exifLines(e)...)
+	}
+	if len(meta.ICC) > 0 || len(meta.XMP) > 0 || len(meta.Text) > 0 {
+		lines = append(lines, "", "Autres métadonnées:")
+	}
+	if len(meta.ICC) > 0 {
+		name := "non supporté"
+		if profile, err := icc.Parse(meta.ICC); profile != nil {
+			name = profileName(profile)
+			if err != nil {
+				name += " (non supporté)"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("- Profil ICC: %s, %s", name, formatFileSize(int64(len(meta.ICC)))))
+	}
+	if len(meta.XMP) > 0 {
+		lines = append(lines, fmt.Sprintf("- XMP: %s", formatFileSize(int64(len(meta.XMP)))))
+	}
+	for _, t := range meta.Text {
+		value := strings.ReplaceAll(t.Value, "\n", " ")
+		if len([]rune(value)) > 50 {
+			value = string([]rune(value)[:47]) + "..."
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", t.Keyword, value))
+	}
+	return lines
+}
+
+// exifLines met en forme les informations EXIF
+func exifLines(e *exif.Exif) []string {
+	lines := []string{"", "Données EXIF:"}
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", label, value))
+		}
+	}
+
+	add("Appareil", e.Camera())
+	add("Objectif", e.LensModel)
+	if !e.DateTime.IsZero() {
+		add("Date de prise de vue", e.DateTime.Format("02/01/2006 15:04:05"))
+	}
+	if e.ExposureTime.Den != 0 {
+		add("Exposition", e.ExposureTime.String()+" s")
+	}
+	if e.FNumber > 0 {
+		add("Ouverture", fmt.Sprintf("f/%.1f", e.FNumber))
+	}
+	if e.ISO > 0 {
+		add("ISO", strconv.Itoa(e.ISO))
+	}
+	if e.FocalLength > 0 {
+		add("Focale", fmt.Sprintf("%.0f mm", e.FocalLength))
+	}
+	if e.ExposureBias != 0 {
+		add("Correction d'exposition", fmt.Sprintf("%+.1f IL", e.ExposureBias))
+	}
+	if e.HasFlash {
+		flash := "non déclenché"
+		if e.Flash {
+			flash = "déclenché"
+		}
+		add("Flash", flash)
+	}
+	add("Orientation", exif.OrientationName(e.Orientation))
+	add("Logiciel", e.Software)
+	add("Auteur", e.Artist)
+	add("Copyright", e.Copyright)
+	if e.GPS != nil {
+		add("GPS", fmt.Sprintf("%.6f, %.6f", e.GPS.Latitude, e.GPS.Longitude))
+		if e.GPS.HasAltitude {
+			add("Altitude", fmt.Sprintf("%.1f m", e.GPS.Altitude))
+		}
+	}
+	return lines
+}
+
+// currentProfile renvoie le profil ICC RVB de l'image chargée, s'il est exploitable
+func currentProfile() *icc.Profile {
+	if currentMetadata == nil || len(currentMetadata.ICC) == 0 {
+		return nil
+	}
+	profile, err := icc.Parse(currentMetadata.ICC)
+	if err != nil {
+		return nil
+	}
+	return profile
+}
+
+func profileName(profile *icc.Profile) string {
+	if profile.Description != "" {
+		return profile.Description
+	}
+	return "profil ICC " + profile.Version
+}
+
+// convertToSRGB convertit les pixels depuis le profil embarqué vers sRGB
+func convertToSRGB(img image.Image) (image.Image, error) {
+	clearScreen()
+	if currentMetadata != nil && len(currentMetadata.ICC) > 0 {
+		if _, err := icc.Parse(currentMetadata.ICC); err != nil {
+			errorMessage(fmt.Sprintf("Profil ICC non supporté: %v", err))
+			time.Sleep(2 * time.Second)
+			return img, nil
+		}
+	}
+	profile := currentProfile()
+	if profile == nil || profile.IsSRGB() {
+		infoMessage("L'image est déjà en sRGB (aucun profil particulier embarqué)")
+		time.Sleep(2 * time.Second)
+		return img, nil
+	}
+
+	infoMessage(fmt.Sprintf("Conversion depuis %s vers sRGB...", profileName(profile)))
+	result := icc.ToSRGB(img, profile)
+	currentMetadata.ICC = icc.SRGB()
+	successMessage("Couleurs converties en sRGB")
+	time.Sleep(1 * time.Second)
+	return result, nil
+}
+
+// withColorProfile ajoute aux métadonnées écrites le profil de couleurs des
+// pixels: le profil d'origine s'ils n'ont pas été convertis, sinon sRGB
+func withColorProfile(meta *metadata.Metadata) *metadata.Metadata {
+	out := &metadata.Metadata{}
+	if meta != nil {
+		*out = *meta
+	}
+	out.ICC = icc.SRGB()
+	if currentMetadata != nil && len(currentMetadata.ICC) > 0 {
+		out.ICC = currentMetadata.ICC
+	}
+	return out
+}
+
 // readMetadata lit et affiche les métadonnées basiques d'une image
 func readMetadata(img image.Image) {
 	clearScreen()
@@ -314,7 +747,7 @@ func readMetadata(img image.Image) {
 	bottomRight := img.At(bounds.Max.X-1, bounds.Max.Y-1)
 	center := img.At((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)
 
-	drawBox("Métadonnées de l'image", []string{
+	lines := []string{
 		fmt.Sprintf("Dimensions: %d × %d pixels", width, height),
 		fmt.Sprintf("Format de couleur: %s", colorModel),
 		fmt.Sprintf("Rectangle: (%d,%d) à (%d,%d)", bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Max.Y),
@@ -325,48 +758,61 @@ func readMetadata(img image.Image) {
 		fmt.Sprintf("- Bas gauche: %v", bottomLeft),
 		fmt.Sprintf("- Bas droite: %v", bottomRight),
 		fmt.Sprintf("- Centre: %v", center),
-	}, 70)
+	}
+	if currentMetadata != nil {
+		lines = append(lines, metadataLines(currentMetadata)...)
+	}
+	drawBox("Métadonnées de l'image", lines, 70)
 
 	readUserInput("Appuyez sur Entrée pour continuer")
 	return
 }
 
-// applyEffectEnhanced applique un effet avec une meilleure UX
-func applyEffectEnhanced(img image.Image) image.Image {
-	clearScreen()
+// applyEffectEnhanced applique un effet au calque actif de doc et renvoie
+// sa nouvelle image; la comparaison montre le rendu de tout le document
+func applyEffectEnhanced(doc *layers.Document) (image.Image, string) {
+	img := doc.ActiveLayer().Image
 	effectItems := []string{
 		"Négatif",
 		"Niveaux de gris", 
 		"Sépia",
 		"Luminosité",
 		"Contraste",
+		"Superposer une image",
+		"Dégradé (ciel, vignettage)",
+		"Filigrane (logo ou texte, par lot)",
 		"Aide",
 		"Retour",
 	}
 
 	effectIcons := []string{
-		"🔄", "⚫", "🟤", "☀️", "🔆", IconHelp, "↩️",
+		"🔄", "⚫", "🟤", "☀️", "🔆", "🖼️", "🌈", "💧", IconHelp, "↩️",
 	}
 
-	drawBox("Appliquer un effet", []string{
-		"Choisissez un effet à appliquer à l'image actuelle",
-		"",
-		"💡 Astuce: Certains effets sont paramétrables",
-		"⚠️ L'effet remplacera l'image actuelle",
-	}, 80)
-	fmt.Println()
-
-	drawMenu("Effets disponibles", effectItems, effectIcons, []string{}, -1, 70)
-
-	choice := promptWithValidation("Choisissez un effet", []string{"1", "2", "3", "4", "5", "6", "7", "h"})
+	choice := selectMenu(func(selected int) {
+		clearScreen()
+		drawBox("Appliquer un effet", []string{
+			"Choisissez un effet à appliquer à l'image actuelle",
+			"",
+			"💡 Astuce: Certains effets sont paramétrables",
+			"👁️ Le résultat est comparé à l'original avant d'être accepté",
+		}, 80)
+		fmt.Println()
+		drawMenu("Effets disponibles", effectItems, effectIcons, []string{}, selected, 70)
+	}, len(effectItems), nil, "Choisissez un effet", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "h"}, "10")
 
-	if choice == "h" || choice == "6" {
+	if choice == "h" || choice == "9" {
 		showHelp("effects")
-		return img
+		return img, ""
 	}
 
-	if choice == "7" {
-		return img
+	if choice == "10" {
+		return img, ""
+	}
+
+	// Le filigrane gère sa comparaison et son éventuel traitement par lot
+	if choice == "8" {
+		return watermarkEnhanced(doc)
 	}
 
 	var effect effects.Effect
@@ -410,10 +856,65 @@ func applyEffectEnhanced(img image.Image) image.Image {
 			factor = 1.0
 		}
 		effect = &effects.ContrastEffect{Factor: factor}
+	case "6":
+		// Superposition: image à poser, position et composition
+		infoMessage("Sélectionnez l'image à superposer")
+		path, err := navigateToFile()
+		if err != nil {
+			warningMessage("Superposition annulée")
+			time.Sleep(1 * time.Second)
+			return img, ""
+		}
+		overlay, err := decodeImageFile(path)
+		if err != nil {
+			errorMessage(err.Error())
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+		ob := overlay.Bounds()
+		fmt.Printf("🖼️ Image superposée: %d × %d pixels\n", ob.Dx(), ob.Dy())
+		fmt.Println("💡 Position du coin supérieur gauche au format X,Y")
+		pos, ok := readPoints(1)
+		if !ok {
+			warningMessage("Position invalide, utilisation de 0,0")
+			pos = []image.Point{{0, 0}}
+		}
+		effect = &effects.CompositeEffect{
+			Source:      overlay,
+			X:           pos[0].X,
+			Y:           pos[0].Y,
+			Compositing: readCompositing(),
+		}
+	case "7":
+		// Dégradé sur toute l'image: ciel en fusion normale, vignettage en
+		// produit d'un blanc central vers du noir
+		infoMessage("Dégradé sur toute l'image")
+		fmt.Println("💡 Ciel: dégradé linéaire vertical, opacité partielle")
+		fmt.Println("💡 Vignettage: dégradé radial blanc vers noir, mode Produit")
+		fmt.Println()
+		fill, ok := askGradient(img.Bounds())
+		if !ok {
+			errorMessageWithTip("Dégradé invalide", "Couleurs au format R,G,B séparées par ; et points au format X,Y")
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+		effect = &effects.GradientOverlayEffect{
+			Fill:        fill.(image.Image),
+			Compositing: readCompositing(),
+		}
 	default:
 		warningMessage("Option invalide, retour au menu principal")
 		time.Sleep(1 * time.Second)
-		return img
+		return img, ""
+	}
+
+	// Sélection facultative: l'effet ne modifie que la zone choisie
+	if confirmAction("Limiter l'effet à une sélection ?") {
+		mask := askSelection(img)
+		if mask == nil {
+			return img, ""
+		}
+		effect = &effects.MaskedEffect{Effect: effect, Mask: mask}
 	}
 
 	clearScreen()
@@ -437,46 +938,184 @@ func applyEffectEnhanced(img image.Image) image.Image {
 	modifiedImg := effect.Apply(img)
 	
 	successMessage("Effet appliqué avec succès!")
+	time.Sleep(1 * time.Second)
+
+	if !compareBeforeAfter(doc.Flatten(), doc.FlattenWith(modifiedImg), effect.Name()) {
+		infoMessage("Effet rejeté, l'image d'origine est conservée")
+		time.Sleep(1 * time.Second)
+		return img, ""
+	}
+
+	successMessage("Effet accepté")
 	infoMessage("Vous pouvez maintenant appliquer d'autres effets ou sauvegarder l'image")
-	time.Sleep(2 * time.Second)
+	time.Sleep(1 * time.Second)
 	
-	return modifiedImg
+	return modifiedImg, effectLabel(effect)
 }
 
-// drawShapeEnhanced dessine une forme avec une meilleure UX
-func drawShapeEnhanced(img image.Image) image.Image {
-	clearScreen()
+// effectLabel décrit l'effet et ses réglages pour l'historique du projet
+func effectLabel(effect effects.Effect) string {
+	switch e := effect.(type) {
+	case *effects.BrightnessEffect:
+		return fmt.Sprintf("%s ×%g", e.Name(), e.Factor)
+	case *effects.ContrastEffect:
+		return fmt.Sprintf("%s ×%g", e.Name(), e.Factor)
+	case *effects.CompositeEffect:
+		return fmt.Sprintf("%s en %d,%d, %s", e.Name(), e.X, e.Y, compositingLabel(e.Compositing))
+	case *effects.GradientOverlayEffect:
+		return e.Name() + ", " + compositingLabel(e.Compositing)
+	case *effects.WatermarkEffect:
+		return fmt.Sprintf("%s, largeur %.0f %%, opacité %.0f %%", e.Name(), e.Scale*100, e.Opacity*100)
+	case *effects.MaskedEffect:
+		return effectLabel(e.Effect) + " (sélection)"
+	}
+	return effect.Name()
+}
+
+// compositingLabel résume des réglages de composition
+func compositingLabel(c effects.Compositing) string {
+	return fmt.Sprintf("opacité %.0f %%, %s, %s", c.Opacity*100, c.Mode, c.Operator)
+}
+
+// compareBeforeAfter affiche l'image avant et après l'effet, séparées à une
+// position réglable ou côte à côte, et renvoie true si l'effet est accepté.
+// Dans un terminal, les flèches ←/→ déplacent la séparation sans Entrée
+func compareBeforeAfter(before, after image.Image, effectName string) bool {
+	split := true
+	pos := 50
+	raw := isTerminal(os.Stdin)
+
+	for {
+		clearScreen()
+		if split {
+			drawPreview(splitView(before, after, float64(pos)/100), fmt.Sprintf("%s: avant | après (séparation à %d%%)", effectName, pos), 7)
+		} else {
+			drawPreview(sideBySide(before, after), effectName+": avant (gauche) | après (droite)", 7)
+		}
+		fmt.Println()
+		fmt.Println("  [a] Accepter  [r] Rejeter  [c] Côte à côte  [s] Séparation")
+		if raw {
+			fmt.Println("  [←] [→] Déplacer la séparation  [0-9] Position par dizaines de %  [Échap] Rejeter")
+		} else {
+			fmt.Println("  [<] [>] Déplacer la séparation  [0-100] Position en %")
+		}
+
+		var choice string
+		if raw {
+			fmt.Print(ColorYellow + IconQuestion + " Votre choix (Entrée = accepter)" + ColorReset + " ")
+			k, ok := waitKey()
+			if !ok {
+				raw = false
+				continue
+			}
+			fmt.Println()
+			switch k.kind {
+			case keyNone:
+				continue
+			case keyLeft:
+				choice = "<"
+			case keyRight:
+				choice = ">"
+			case keyHome:
+				choice = "0"
+			case keyEnd:
+				choice = "100"
+			case keyEnter:
+				choice = ""
+			case keyEscape:
+				choice = "r"
+			case keyRune:
+				choice = strings.ToLower(string(k.r))
+				if k.r >= '0' && k.r <= '9' {
+					choice = strconv.Itoa(int(k.r-'0') * 10)
+				}
+			default:
+				continue
+			}
+		} else {
+			choice = strings.ToLower(readUserInput("Votre choix (Entrée = accepter)"))
+		}
+		switch choice {
+		case "", "a", "o":
+			return true
+		case "r", "n":
+			return false
+		case "c":
+			split = false
+		case "s":
+			split = true
+		case "<":
+			split = true
+			pos = max(0, pos-10)
+		case ">":
+			split = true
+			pos = min(100, pos+10)
+		default:
+			if p, err := strconv.Atoi(choice); err == nil && p >= 0 && p <= 100 {
+				split = true
+				pos = p
+			} else {
+				warningMessage("Option invalide")
+				time.Sleep(1 * time.Second)
+			}
+		}
+	}
+}
+
+// drawShapeEnhanced dessine sur le calque layer, dont l'opacité et le mode
+// de fusion reçoivent ceux choisis pour la forme; backdrop est le rendu du
+// document sur lequel la forme sera posée, dont le pot de peinture compare
+// les couleurs
+func drawShapeEnhanced(layer *layers.Layer, backdrop image.Image) (image.Image, string) {
+	img := layer.Image
 	shapeItems := []string{
 		"Carré",
 		"Cercle",
+		"Triangle",
+		"Ligne",
+		"Forme vectorielle (contour, courbes)",
+		"Texte",
+		"Pot de peinture",
 		"Aide",
 		"Retour",
 	}
 
 	shapeIcons := []string{
-		"⬜", "⭕", IconHelp, "↩️",
+		"⬜", "⭕", "🔺", "📏", "✏️", "🔤", "🪣", IconHelp, "↩️",
 	}
 
 	bounds := img.Bounds()
-	drawBox("Dessiner une forme", []string{
-		"Choisissez une forme à dessiner sur l'image",
-		"",
-		fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
-		"💡 Astuce: Vérifiez que la forme reste dans les limites",
-	}, 80)
-	fmt.Println()
+	choice := selectMenu(func(selected int) {
+		clearScreen()
+		drawBox("Dessiner une forme", []string{
+			"Choisissez une forme à dessiner sur l'image",
+			"",
+			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+			"💡 Astuce: Vérifiez que la forme reste dans les limites",
+		}, 80)
+		fmt.Println()
+		drawMenu("Formes disponibles", shapeItems, shapeIcons, []string{}, selected, 70)
+	}, len(shapeItems), nil, "Choisissez une forme", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "h"}, "9")
 
-	drawMenu("Formes disponibles", shapeItems, shapeIcons, []string{}, -1, 70)
+	if choice == "h" || choice == "8" {
+		showHelp("shapes")
+		return img, ""
+	}
 
-	choice := promptWithValidation("Choisissez une forme", []string{"1", "2", "3", "4", "h"})
+	if choice == "9" {
+		return img, ""
+	}
 
-	if choice == "h" || choice == "3" {
-		showHelp("shapes")
-		return img
+	if choice == "5" {
+		return drawStyledShape(layer)
 	}
 
-	if choice == "4" {
-		return img
+	if choice == "6" {
+		return drawText(layer)
+	}
+
+	if choice == "7" {
+		return drawFloodFill(layer, backdrop)
 	}
 
 	// Définition de la couleur avec aide
@@ -491,25 +1130,17 @@ func drawShapeEnhanced(img image.Image) image.Image {
 	fmt.Println("  • 0,255,255 = Cyan")
 	fmt.Println("  • 0,0,0 = Noir")
 	fmt.Println("  • 255,255,255 = Blanc")
+	fmt.Println("  • d = Dégradé linéaire, radial ou conique")
 	fmt.Println()
 
-	colorInput := readUserInput("Couleur au format R,G,B (ex: 255,0,0)")
-	colorParts := strings.Split(colorInput, ",")
-	if len(colorParts) != 3 {
-		warningMessage("Format de couleur invalide, utilisation du rouge par défaut")
-		colorParts = []string{"255", "0", "0"}
+	colorInput := readUserInput("Couleur au format R,G,B (ex: 255,0,0), ou d pour un dégradé")
+	shapeColor, ok := readPaint(colorInput, bounds)
+	if !ok {
+		warningMessage("Couleur invalide, utilisation du rouge par défaut")
+		shapeColor = color.RGBA{255, 0, 0, 255}
 	}
-
-	r, err1 := strconv.Atoi(strings.TrimSpace(colorParts[0]))
-	g, err2 := strconv.Atoi(strings.TrimSpace(colorParts[1]))
-	b, err3 := strconv.Atoi(strings.TrimSpace(colorParts[2]))
-	
-	if err1 != nil || err2 != nil || err3 != nil || r < 0 || r > 255 || g < 0 || g > 255 || b < 0 || b > 255 {
-		warningMessage("Valeurs de couleur invalides, utilisation du rouge par défaut")
-		r, g, b = 255, 0, 0
-	}
-	
-	shapeColor := color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+	colorLabel := paintLabel(shapeColor)
+	askCompositing(layer)
 
 	switch choice {
 	case "1": // Carré
@@ -517,7 +1148,7 @@ func drawShapeEnhanced(img image.Image) image.Image {
 		drawBox("Paramètres du carré", []string{
 			"Définissez la position et la taille du carré",
 			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
-			fmt.Sprintf("🎨 Couleur sélectionnée: RGB(%d,%d,%d)", r, g, b),
+			"🎨 Couleur sélectionnée: " + colorLabel,
 			"",
 			"💡 Conseils:",
 			"  • X=0, Y=0 = coin supérieur gauche",
@@ -546,20 +1177,20 @@ func drawShapeEnhanced(img image.Image) image.Image {
 		if err1 != nil || err2 != nil || err3 != nil || x < 0 || y < 0 || size <= 0 {
 			errorMessageWithTip("Valeurs invalides", "Utilisez des nombres positifs")
 			time.Sleep(2 * time.Second)
-			return img
+			return img, ""
 		}
 
 		if x+size > bounds.Dx() || y+size > bounds.Dy() {
 			errorMessageWithTip("Le carré dépasse les limites de l'image", fmt.Sprintf("Réduisez la taille ou ajustez la position (image: %dx%d)", bounds.Dx(), bounds.Dy()))
 			time.Sleep(2 * time.Second)
-			return img
+			return img, ""
 		}
 
 		clearScreen()
 		drawBox("Dessin du carré", []string{
 			fmt.Sprintf("Position: (%d, %d)", x, y),
 			fmt.Sprintf("Taille: %d pixels", size),
-			fmt.Sprintf("Couleur: RGB(%d,%d,%d)", r, g, b),
+			"Couleur: " + colorLabel,
 		}, 80)
 		fmt.Println()
 
@@ -572,7 +1203,7 @@ func drawShapeEnhanced(img image.Image) image.Image {
 			}
 		}
 
-		squareEffect := &SquareEffect{
+		squareEffect := &effects.SquareEffect{
 			X:     x,
 			Y:     y,
 			Size:  size,
@@ -581,14 +1212,14 @@ func drawShapeEnhanced(img image.Image) image.Image {
 		modifiedImg := squareEffect.Apply(img)
 		successMessage("Carré dessiné avec succès!")
 		time.Sleep(2 * time.Second)
-		return modifiedImg
+		return modifiedImg, "Carré"
 
 	case "2": // Cercle
 		clearScreen()
 		drawBox("Paramètres du cercle", []string{
 			"Définissez la position et le rayon du cercle",
 			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
-			fmt.Sprintf("🎨 Couleur sélectionnée: RGB(%d,%d,%d)", r, g, b),
+			"🎨 Couleur sélectionnée: " + colorLabel,
 			"",
 			"💡 Conseils:",
 			"  • Centre X, Y = position du centre du cercle",
@@ -611,64 +1242,620 @@ func drawShapeEnhanced(img image.Image) image.Image {
 		xStr := readUserInput("Centre X")
 		yStr := readUserInput("Centre Y") 
 		radiusStr := readUserInput("Rayon en pixels")
+		antiAlias := askAntiAlias()
+
+		x, err1 := strconv.Atoi(xStr)
+		y, err2 := strconv.Atoi(yStr)
+		radius, err3 := strconv.Atoi(radiusStr)
+
+		if err1 != nil || err2 != nil || err3 != nil || x < 0 || y < 0 || radius <= 0 {
+			errorMessageWithTip("Valeurs invalides", "Utilisez des nombres positifs")
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+
+		// Vérifier que le centre du cercle est dans l'image et qu'au moins une partie du cercle sera visible
+		if x < 0 || x >= bounds.Dx() || y < 0 || y >= bounds.Dy() {
+			errorMessageWithTip("Le centre du cercle doit être dans l'image", fmt.Sprintf("Utilisez des coordonnées entre 0 et %dx%d", bounds.Dx()-1, bounds.Dy()-1))
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+		
+		// Avertir si le cercle dépasse largement les limites (mais permettre quand même)
+		if x+radius < 0 || x-radius >= bounds.Dx() || y+radius < 0 || y-radius >= bounds.Dy() {
+			errorMessageWithTip("Le cercle est entièrement en dehors de l'image", fmt.Sprintf("Ajustez le centre ou le rayon (image: %dx%d)", bounds.Dx(), bounds.Dy()))
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+
+		clearScreen()
+		drawBox("Dessin du cercle", []string{
+			fmt.Sprintf("Centre: (%d, %d)", x, y),
+			fmt.Sprintf("Rayon: %d pixels", radius),
+			"Couleur: " + colorLabel,
+		}, 80)
+		fmt.Println()
+
+		// Simulation de progression
+		for i := 0; i <= 100; i += 10 {
+			drawProgressBarAnimated(float64(i)/100.0, 50, "Dessin du cercle")
+			time.Sleep(50 * time.Millisecond)
+			if i < 100 {
+				fmt.Print("\033[1A\r") // Remonte d'une ligne
+			}
+		}
+
+		circleEffect := &effects.CircleEffect{
+			CenterX:   x,
+			CenterY:   y,
+			Radius:    radius,
+			Color:     shapeColor,
+			AntiAlias: antiAlias,
+		}
+		modifiedImg := circleEffect.Apply(img)
+		successMessage("Cercle dessiné avec succès!")
+		time.Sleep(2 * time.Second)
+		return modifiedImg, "Cercle"
+
+	case "3": // Triangle
+		clearScreen()
+		drawBox("Paramètres du triangle", []string{
+			"Définissez les trois sommets du triangle",
+			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+			"🎨 Couleur sélectionnée: " + colorLabel,
+			"",
+			"💡 Format des sommets: X,Y (ex: 100,50)",
+		}, 80)
+		fmt.Println()
+
+		points, ok := readPoints(3)
+		if !ok {
+			errorMessageWithTip("Sommets invalides", "Utilisez le format X,Y avec des nombres entiers")
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+		antiAlias := askAntiAlias()
+
+		triangleEffect := &effects.TriangleEffect{
+			X1: points[0].X, Y1: points[0].Y,
+			X2: points[1].X, Y2: points[1].Y,
+			X3: points[2].X, Y3: points[2].Y,
+			Color:     shapeColor,
+			AntiAlias: antiAlias,
+		}
+		modifiedImg := triangleEffect.Apply(img)
+		successMessage("Triangle dessiné avec succès!")
+		time.Sleep(2 * time.Second)
+		return modifiedImg, "Triangle"
+
+	case "4": // Ligne
+		clearScreen()
+		drawBox("Paramètres de la ligne", []string{
+			"Définissez les deux extrémités de la ligne",
+			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+			"🎨 Couleur sélectionnée: " + colorLabel,
+			"",
+			"💡 Format des extrémités: X,Y (ex: 0,0)",
+		}, 80)
+		fmt.Println()
+
+		points, ok := readPoints(2)
+		if !ok {
+			errorMessageWithTip("Extrémités invalides", "Utilisez le format X,Y avec des nombres entiers")
+			time.Sleep(2 * time.Second)
+			return img, ""
+		}
+		antiAlias := askAntiAlias()
+
+		lineEffect := &effects.LineEffect{
+			X1: points[0].X, Y1: points[0].Y,
+			X2: points[1].X, Y2: points[1].Y,
+			Color:     shapeColor,
+			AntiAlias: antiAlias,
+		}
+		modifiedImg := lineEffect.Apply(img)
+		successMessage("Ligne dessinée avec succès!")
+		time.Sleep(2 * time.Second)
+		return modifiedImg, "Ligne"
+
+	default:
+		warningMessage("Option invalide, retour au menu principal")
+		time.Sleep(1 * time.Second)
+		return img, ""
+	}
+}
+
+// drawStyledShape dessine une forme vectorielle avec remplissage et contour
+// paramétrables
+func drawStyledShape(layer *layers.Layer) (image.Image, string) {
+	img := layer.Image
+	bounds := img.Bounds()
+	items := []string{
+		"Rectangle",
+		"Rectangle arrondi",
+		"Cercle",
+		"Ellipse",
+		"Triangle",
+		"Polygone régulier",
+		"Étoile",
+		"Flèche",
+		"Polyligne",
+		"Courbe de Bézier",
+		"Retour",
+	}
+	choice := selectMenu(func(selected int) {
+		clearScreen()
+		drawBox("Forme vectorielle", []string{
+			"Remplissage et contour sont indépendants",
+			fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+			"",
+			"💡 Le contour est centré sur le bord de la forme",
+		}, 80)
+		fmt.Println()
+		drawMenu("Formes", items, []string{"▭", "▢", "⭕", "⬭", "🔺", "⬡", "⭐", "➡️", "〰️", "➰", "↩️"}, []string{}, selected, 70)
+	}, len(items), nil, "Choisissez une forme", []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}, "11")
+	invalid := func() (image.Image, string) {
+		errorMessageWithTip("Valeurs invalides", "Utilisez des nombres entiers, X,Y pour les points")
+		time.Sleep(2 * time.Second)
+		return img, ""
+	}
+	// readInts lit des entiers strictement positifs
+	readInts := func(prompts ...string) ([]int, bool) {
+		values := make([]int, len(prompts))
+		for i, prompt := range prompts {
+			v, err := strconv.Atoi(strings.TrimSpace(readUserInput(prompt)))
+			if err != nil || v <= 0 {
+				return nil, false
+			}
+			values[i] = v
+		}
+		return values, true
+	}
+
+	var shape effects.Shape
+	var open bool
+	rule := effects.NonZero
+	switch choice {
+	case "1", "2":
+		fmt.Println("💡 Coin supérieur gauche au format X,Y")
+		p, ok := readPoints(1)
+		if !ok {
+			return invalid()
+		}
+		size, ok := readInts("Largeur en pixels", "Hauteur en pixels")
+		if !ok {
+			return invalid()
+		}
+		if choice == "1" {
+			shape = effects.Rectangle(p[0].X, p[0].Y, size[0], size[1])
+			break
+		}
+		radius, ok := readInts("Rayon des coins en pixels")
+		if !ok {
+			return invalid()
+		}
+		shape = effects.RoundedRect(p[0].X, p[0].Y, size[0], size[1], radius[0])
+	case "3", "4", "6", "7":
+		fmt.Println("💡 Centre au format X,Y")
+		c, ok := readPoints(1)
+		if !ok {
+			return invalid()
+		}
+		switch choice {
+		case "3":
+			v, ok := readInts("Rayon en pixels")
+			if !ok {
+				return invalid()
+			}
+			shape = effects.Circle(c[0].X, c[0].Y, v[0])
+		case "4":
+			v, ok := readInts("Rayon horizontal", "Rayon vertical")
+			if !ok {
+				return invalid()
+			}
+			shape = effects.Ellipse(c[0].X, c[0].Y, v[0], v[1])
+		case "6":
+			v, ok := readInts("Rayon en pixels", "Nombre de côtés (3 ou plus)")
+			if !ok || v[1] < 3 {
+				return invalid()
+			}
+			shape = effects.RegularPolygon(c[0].X, c[0].Y, v[0], v[1])
+		case "7":
+			v, ok := readInts("Rayon extérieur", "Rayon intérieur", "Nombre de branches (3 ou plus)")
+			if !ok || v[2] < 3 {
+				return invalid()
+			}
+			shape = effects.Star(c[0].X, c[0].Y, v[0], v[1], v[2])
+		}
+	case "5":
+		p, ok := readPoints(3)
+		if !ok {
+			return invalid()
+		}
+		shape = effects.Triangle(p[0].X, p[0].Y, p[1].X, p[1].Y, p[2].X, p[2].Y)
+	case "8":
+		fmt.Println("💡 Origine puis pointe au format X,Y")
+		p, ok := readPoints(2)
+		if !ok {
+			return invalid()
+		}
+		width, ok := readInts("Épaisseur du corps en pixels")
+		if !ok {
+			return invalid()
+		}
+		shape = effects.Arrow(p[0].X, p[0].Y, p[1].X, p[1].Y, width[0])
+	case "9":
+		n, ok := readInts("Nombre de points (2 ou plus)")
+		if !ok || n[0] < 2 {
+			return invalid()
+		}
+		p, ok := readPoints(n[0])
+		if !ok {
+			return invalid()
+		}
+		closed := confirmAction("Fermer la polyligne ?")
+		open = !closed
+		shape = effects.Polyline(p, closed)
+		// Une polyligne peut se recouper: la règle choisit les zones pleines
+		if readUserInput("Remplissage des zones recoupées: 1 non nul, 2 pair-impair (Entrée = non nul)") == "2" {
+			rule = effects.EvenOdd
+		}
+	case "10":
+		fmt.Println("💡 Départ, contrôle 1, contrôle 2 puis arrivée au format X,Y")
+		p, ok := readPoints(4)
+		if !ok {
+			return invalid()
+		}
+		path := &effects.Path{}
+		pt := func(i int) (float64, float64) { return float64(p[i].X) + 0.5, float64(p[i].Y) + 0.5 }
+		path.MoveTo(pt(0))
+		x1, y1 := pt(1)
+		x2, y2 := pt(2)
+		x3, y3 := pt(3)
+		path.CubicTo(x1, y1, x2, y2, x3, y3)
+		open = !confirmAction("Fermer la courbe ?")
+		if !open {
+			path.Close()
+		}
+		shape = path
+	default:
+		return img, ""
+	}
+
+	// Remplissage (vide = aucun)
+	var fill color.Color
+	if input := readUserInput("Couleur de remplissage R,G,B, d pour un dégradé (Entrée = aucun)"); input != "" {
+		c, ok := readPaint(input, bounds)
+		if !ok {
+			return invalid()
+		}
+		fill = c
+	}
+
+	stroke, ok := askStroke(open, bounds)
+	if !ok {
+		return invalid()
+	}
+	if fill == nil && stroke == nil {
+		warningMessage("Ni remplissage ni contour: rien à dessiner")
+		time.Sleep(2 * time.Second)
+		return img, ""
+	}
+
+	shapeEffect := &effects.StyledShapeEffect{
+		Shape:     shape,
+		Fill:      fill,
+		Stroke:    stroke,
+		FillRule:  rule,
+		AntiAlias: askAntiAlias(),
+	}
+	askCompositing(layer)
+	modifiedImg := shapeEffect.Apply(img)
+	successMessage("Forme dessinée avec succès!")
+	time.Sleep(2 * time.Second)
+	n, _ := strconv.Atoi(choice)
+	return modifiedImg, items[n-1]
+}
+
+// drawText écrit un texte multiligne avec la police bitmap intégrée
+func drawText(layer *layers.Layer) (image.Image, string) {
+	img := layer.Image
+	clearScreen()
+	bounds := img.Bounds()
+	drawBox("Texte", []string{
+		"Police bitmap 8 × 16 intégrée (Latin-1, accents français, €, œ)",
+		"ou police TrueType (.ttf) à la taille de votre choix",
+		fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+		"",
+		"💡 Saisissez une ligne à la fois, Entrée sur une ligne vide pour terminer",
+	}, 80)
+	fmt.Println()
+
+	var lines []string
+	for {
+		line := readUserInput(fmt.Sprintf("Ligne %d", len(lines)+1))
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		warningMessage("Aucun texte saisi")
+		time.Sleep(1 * time.Second)
+		return img, ""
+	}
+
+	text := &effects.TextEffect{Text: strings.Join(lines, "\n"), Scale: 1}
+	padding := 2
+	if path := readUserInput("Police TrueType (chemin d'un fichier .ttf, Entrée = police intégrée)"); path != "" {
+		font, err := truetype.Open(path)
+		if err != nil {
+			warningMessage(fmt.Sprintf("Police illisible (%v), utilisation de la police intégrée", err))
+		} else {
+			infoMessage("Police chargée: " + font.Name)
+			text.Font = font
+		}
+	}
+	if text.Font != nil {
+		size, err := strconv.ParseFloat(readUserInput("Taille en points (1 point = 1 pixel, Entrée = 32)"), 64)
+		if err != nil || size <= 0 {
+			size = 32
+		}
+		text.FontSize = size
+		padding = int(size / 8)
+	} else if v, err := strconv.Atoi(readUserInput("Taille (facteur d'agrandissement, Entrée = 1)")); err == nil && v >= 1 {
+		text.Scale = v
+		padding = 2 * v
+	}
+
+	c, ok := readPaint(readUserInput("Couleur du texte R,G,B (ex: 255,255,255), d pour un dégradé"), bounds)
+	if !ok {
+		warningMessage("Couleur invalide, utilisation du blanc")
+		c = color.RGBA{255, 255, 255, 255}
+	}
+	text.Color = c
+	if input := readUserInput("Couleur du cadre de fond R,G,B (Entrée = aucun)"); input != "" {
+		if bg, ok := parseRGB(input); ok {
+			text.Background = bg
+			text.Padding = padding
+		} else {
+			warningMessage("Couleur invalide, texte sans cadre")
+		}
+	}
+
+	if len(lines) > 1 {
+		switch readUserInput("Alignement: 1 gauche, 2 centré, 3 droite (Entrée = gauche)") {
+		case "2":
+			text.Align = effects.AlignCenter
+		case "3":
+			text.Align = effects.AlignRight
+		}
+	}
+
+	fmt.Println("📍 Position:")
+	for a := effects.AnchorPoint; a <= effects.AnchorBottomRight; a++ {
+		fmt.Printf("  %d. %s\n", int(a)+1, a)
+	}
+	if v, err := strconv.Atoi(readUserInput("Position (Entrée = coordonnées)")); err == nil && v >= 2 && v <= int(effects.AnchorBottomRight)+1 {
+		text.Anchor = effects.Anchor(v - 1)
+		if m, err := strconv.Atoi(readUserInput("Marge aux bords en pixels (Entrée = 10)")); err == nil && m >= 0 {
+			text.Margin = m
+		} else {
+			text.Margin = 10
+		}
+	} else {
+		fmt.Println("💡 Coin supérieur gauche du texte au format X,Y")
+		p, ok := readPoints(1)
+		if !ok {
+			warningMessage("Position invalide, utilisation de 0,0")
+			p = []image.Point{{0, 0}}
+		}
+		text.X, text.Y = p[0].X, p[0].Y
+	}
+	askCompositing(layer)
+
+	modifiedImg := text.Apply(img)
+	successMessage("Texte écrit avec succès!")
+	time.Sleep(2 * time.Second)
+	return modifiedImg, "Texte"
+}
+
+// drawFloodFill remplit la zone de couleur proche d'un point (pot de
+// peinture); les couleurs comparées sont celles de backdrop
+func drawFloodFill(layer *layers.Layer, backdrop image.Image) (image.Image, string) {
+	img := layer.Image
+	clearScreen()
+	bounds := img.Bounds()
+	drawBox("Pot de peinture", []string{
+		"Remplit la zone dont la couleur est proche de celle du point de départ",
+		fmt.Sprintf("🖼️ Dimensions de l'image: %d × %d pixels", bounds.Dx(), bounds.Dy()),
+		"",
+		"💡 Tolérance 0 = couleur identique, 100 = toute l'image",
+	}, 80)
+	fmt.Println()
 
-		x, err1 := strconv.Atoi(xStr)
-		y, err2 := strconv.Atoi(yStr)
-		radius, err3 := strconv.Atoi(radiusStr)
+	fmt.Println("💡 Point de départ au format X,Y")
+	p, ok := readPoints(1)
+	if !ok || !p[0].In(bounds) {
+		errorMessageWithTip("Point invalide", fmt.Sprintf("Utilisez des coordonnées entre 0,0 et %d,%d", bounds.Max.X-1, bounds.Max.Y-1))
+		time.Sleep(2 * time.Second)
+		return img, ""
+	}
+	fill, ok := readPaint(readUserInput("Couleur de remplissage R,G,B, d pour un dégradé"), bounds)
+	if !ok {
+		errorMessageWithTip("Couleur invalide", "Utilisez le format R,G,B (ex: 255,0,0)")
+		time.Sleep(2 * time.Second)
+		return img, ""
+	}
 
-		if err1 != nil || err2 != nil || err3 != nil || x < 0 || y < 0 || radius <= 0 {
-			errorMessageWithTip("Valeurs invalides", "Utilisez des nombres positifs")
-			time.Sleep(2 * time.Second)
-			return img
+	effect := &effects.FloodFillEffect{X: p[0].X, Y: p[0].Y, Fill: fill, Tolerance: 10, Sample: backdrop}
+	if input := readUserInput("Tolérance de 0 à 100 (Entrée = 10)"); input != "" {
+		v, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil || v < 0 || v > 100 {
+			warningMessage("Tolérance invalide, utilisation de 10")
+		} else {
+			effect.Tolerance = v
 		}
+	}
+	if readUserInput("Écart de couleur: 1 RGB, 2 Lab perceptuel (Entrée = RGB)") == "2" {
+		effect.Distance = effects.LabDistance
+	}
+	effect.Global = confirmAction("Remplacer aussi les zones proches non reliées au point ?")
+	if !effect.Global && readUserInput("Voisinage: 1 par les côtés, 2 aussi par les coins (Entrée = côtés)") == "2" {
+		effect.Connectivity = effects.EightWay
+	}
+	askCompositing(layer)
 
-		// Vérifier que le centre du cercle est dans l'image et qu'au moins une partie du cercle sera visible
-		if x < 0 || x >= bounds.Dx() || y < 0 || y >= bounds.Dy() {
-			errorMessageWithTip("Le centre du cercle doit être dans l'image", fmt.Sprintf("Utilisez des coordonnées entre 0 et %dx%d", bounds.Dx()-1, bounds.Dy()-1))
-			time.Sleep(2 * time.Second)
-			return img
+	modifiedImg := effect.Apply(img)
+	successMessage("Zone remplie avec succès!")
+	time.Sleep(2 * time.Second)
+	return modifiedImg, "Pot de peinture"
+}
+
+// askCompositing règle l'opacité, le mode de fusion et l'opérateur de
+// Porter-Duff du calque d'une forme, appliqués à l'aplatissement aux seuls
+// pixels que la forme couvre
+func askCompositing(layer *layers.Layer) {
+	if !confirmAction("Régler l'opacité ou le mode de fusion ?") {
+		return
+	}
+	comp := readCompositing()
+	layer.Opacity, layer.Mode, layer.Operator = comp.Opacity, comp.Mode, comp.Operator
+}
+
+// readCompositing demande les réglages de composition (Entrée = valeurs par défaut)
+func readCompositing() effects.Compositing {
+	comp := effects.Compositing{Opacity: 1}
+	if input := readUserInput("Opacité en % (Entrée = 100)"); input != "" {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(input), "%"), 64)
+		if err != nil || v < 0 || v > 100 {
+			warningMessage("Opacité invalide, utilisation de 100%")
+		} else {
+			comp.Opacity = v / 100
 		}
-		
-		// Avertir si le cercle dépasse largement les limites (mais permettre quand même)
-		if x+radius < 0 || x-radius >= bounds.Dx() || y+radius < 0 || y-radius >= bounds.Dy() {
-			errorMessageWithTip("Le cercle est entièrement en dehors de l'image", fmt.Sprintf("Ajustez le centre ou le rayon (image: %dx%d)", bounds.Dx(), bounds.Dy()))
-			time.Sleep(2 * time.Second)
-			return img
+	}
+
+	fmt.Println("🎨 Modes de fusion:")
+	for m := effects.Normal; m <= effects.Difference; m++ {
+		fmt.Printf("  %d. %s\n", int(m)+1, m)
+	}
+	if v, err := strconv.Atoi(readUserInput("Mode de fusion (Entrée = Normal)")); err == nil && v >= 1 && v <= int(effects.Difference)+1 {
+		comp.Mode = effects.BlendMode(v - 1)
+	}
+
+	fmt.Println("🧩 Opérateurs de Porter-Duff:")
+	for o := effects.SrcOver; o <= effects.Clear; o++ {
+		fmt.Printf("  %d. %s\n", int(o)+1, o)
+	}
+	if v, err := strconv.Atoi(readUserInput("Opérateur (Entrée = source sur destination)")); err == nil && v >= 1 && v <= int(effects.Clear)+1 {
+		comp.Operator = effects.Operator(v - 1)
+	}
+	return comp
+}
+
+// decodeImageFile décode une image annexe (superposition, filigrane...) sans
+// toucher aux métadonnées de l'image courante
+func decodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("impossible d'ouvrir le fichier: %v", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err == image.ErrFormat && strings.EqualFold(filepath.Ext(path), ".tga") {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			img, err = tga.Decode(file)
 		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("impossible de décoder l'image: %v", err)
+	}
+	return img, nil
+}
 
-		clearScreen()
-		drawBox("Dessin du cercle", []string{
-			fmt.Sprintf("Centre: (%d, %d)", x, y),
-			fmt.Sprintf("Rayon: %d pixels", radius),
-			fmt.Sprintf("Couleur: RGB(%d,%d,%d)", r, g, b),
-		}, 80)
-		fmt.Println()
+// askStroke lit les paramètres du contour; nil si aucun contour n'est voulu.
+// Les extrémités ne sont demandées que pour un tracé ouvert ou pointillé
+func askStroke(open bool, bounds image.Rectangle) (*effects.Stroke, bool) {
+	input := readUserInput("Couleur du contour R,G,B, d pour un dégradé (Entrée = aucun)")
+	if input == "" {
+		return nil, true
+	}
+	c, ok := readPaint(input, bounds)
+	if !ok {
+		return nil, false
+	}
+	width, err := strconv.ParseFloat(strings.TrimSpace(readUserInput("Épaisseur du contour en pixels (ex: 3)")), 64)
+	if err != nil || width <= 0 {
+		return nil, false
+	}
+	stroke := &effects.Stroke{Width: width, Color: c}
 
-		// Simulation de progression
-		for i := 0; i <= 100; i += 10 {
-			drawProgressBarAnimated(float64(i)/100.0, 50, "Dessin du cercle")
-			time.Sleep(50 * time.Millisecond)
-			if i < 100 {
-				fmt.Print("\033[1A\r") // Remonte d'une ligne
+	if input := readUserInput("Pointillés: longueurs trait,espace (ex: 10,5 ; Entrée = continu)"); input != "" {
+		for _, part := range strings.Split(input, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || v < 0 {
+				return nil, false
 			}
+			stroke.Dash = append(stroke.Dash, v)
 		}
+	}
 
-		circleEffect := &CircleEffect{
-			CenterX: x,
-			CenterY: y,
-			Radius:  radius,
-			Color:   shapeColor,
+	if open || len(stroke.Dash) > 0 {
+		switch readUserInput("Extrémités: 1 nettes, 2 arrondies, 3 carrées (Entrée = nettes)") {
+		case "2":
+			stroke.Cap = effects.RoundCap
+		case "3":
+			stroke.Cap = effects.SquareCap
 		}
-		modifiedImg := circleEffect.Apply(img)
-		successMessage("Cercle dessiné avec succès!")
-		time.Sleep(2 * time.Second)
-		return modifiedImg
+	}
+	switch readUserInput("Angles: 1 pointus, 2 arrondis, 3 biseautés (Entrée = pointus)") {
+	case "2":
+		stroke.Join = effects.RoundJoin
+	case "3":
+		stroke.Join = effects.BevelJoin
+	}
+	return stroke, true
+}
 
-	default:
-		warningMessage("Option invalide, retour au menu principal")
-		time.Sleep(1 * time.Second)
-		return img
+// parseRGB lit une couleur au format R,G,B
+func parseRGB(s string) (color.RGBA, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return color.RGBA{}, false
+	}
+	var v [3]uint8
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 || n > 255 {
+			return color.RGBA{}, false
+		}
+		v[i] = uint8(n)
+	}
+	return color.RGBA{v[0], v[1], v[2], 255}, true
+}
+
+// readPoints lit n points au format X,Y
+func readPoints(n int) ([]image.Point, bool) {
+	points := make([]image.Point, n)
+	for i := range points {
+		parts := strings.Split(readUserInput(fmt.Sprintf("Point %d (X,Y)", i+1)), ",")
+		if len(parts) != 2 {
+			return nil, false
+		}
+		x, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+		y, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err1 != nil || err2 != nil {
+			return nil, false
+		}
+		points[i] = image.Point{x, y}
 	}
+	return points, true
+}
+
+// askAntiAlias demande si les bords de la forme doivent être adoucis
+func askAntiAlias() bool {
+	return confirmAction("Anticrénelage (bords adoucis) ?")
 }
 
 // resizeImage redimensionne l'image selon les dimensions spécifiées
@@ -1014,72 +2201,12 @@ func convertImage(img image.Image) error {
 
 
 
-type SquareEffect struct {
-	X, Y, Size int
-	Color      color.Color
-}
-
-func (s *SquareEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-
-	// copie
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-
-	// carré
-	for y := s.Y; y < s.Y+s.Size && y < bounds.Max.Y; y++ {
-		for x := s.X; x < s.X+s.Size && x < bounds.Max.X; x++ {
-			if x >= bounds.Min.X && y >= bounds.Min.Y {
-				result.Set(x, y, s.Color)
-			}
-		}
-	}
-	return result
-}
-
-type CircleEffect struct {
-	CenterX, CenterY, Radius int
-	Color                    color.Color
-}
-
-func (c *CircleEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-
-	// copie
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-
-	// cercle
-	for y := c.CenterY - c.Radius; y <= c.CenterY+c.Radius; y++ {
-		for x := c.CenterX - c.Radius; x <= c.CenterX+c.Radius; x++ {
-			dx := x - c.CenterX
-			dy := y - c.CenterY
-			if dx*dx+dy*dy <= c.Radius*c.Radius {
-				if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					result.Set(x, y, c.Color)
-				}
-			}
-		}
-	}
-	return result
-}
-
-
-
 func saveImageEnhanced(img image.Image) error {
 	clearScreen()
 	drawBox("Sauvegarder l'image", []string{
 		"Entrez le chemin où sauvegarder l'image modifiée",
 		"",
-		"📁 Extensions supportées: .png, .jpg, .jpeg",
+		"📁 Extensions supportées: .png, .jpg, .jpeg, .tif, .tiff, .qoi, .tga, .ico, .cur, .pbm, .pgm, .ppm, .pnm, .pam",
 		"💡 Astuce: Utilisez des noms explicites (ex: image_effet_sepia.png)",
 		"⚠️ Attention: Un fichier existant sera écrasé",
 	}, 80)
@@ -1110,6 +2237,33 @@ func saveImageEnhanced(img image.Image) error {
 		}
 	}
 
+	// Variante ASCII possible pour PBM/PGM/PPM (PAM est toujours binaire)
+	plainNetpbm := false
+	if ext == ".pbm" || ext == ".pgm" || ext == ".ppm" || ext == ".pnm" {
+		plainNetpbm = confirmAction("Encodage ASCII (plain) au lieu du binaire ?")
+	}
+
+	tiffCompression := tiff.Uncompressed
+	if ext == ".tif" || ext == ".tiff" {
+		tiffCompression = askTIFFCompression()
+	}
+
+	var iconSizes []int
+	if ext == ".ico" || ext == ".cur" {
+		iconSizes = askIconSizes()
+	}
+
+	var tgaOptions *tga.Options
+	if ext == ".tga" {
+		tgaOptions = askTGAOptions()
+	}
+
+	// Seuls JPEG et PNG transportent les métadonnées de la session
+	var meta *metadata.Metadata
+	if ext == ".png" || ext == ".jpg" || ext == ".jpeg" {
+		meta = withColorProfile(currentMetadata.Filter(askMetadataMode(currentMetadata)))
+	}
+
 	dir := filepath.Dir(filePath)
 	if dir != "." {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -1149,11 +2303,24 @@ func saveImageEnhanced(img image.Image) error {
 
 	switch ext {
 	case ".png":
-		err = png.Encode(file, img)
+		err = metadata.EncodePNG(file, img, meta)
 	case ".jpg", ".jpeg":
-		err = jpeg.Encode(file, img, &jpeg.Options{Quality: 90})
+		err = metadata.EncodeJPEG(file, img, &jpeg.Options{Quality: 90}, meta)
+	case ".pbm", ".pgm", ".ppm", ".pnm", ".pam":
+		err = netpbm.Encode(file, img, &netpbm.Options{
+			Format: netpbm.FormatFromExt(ext),
+			Plain:  plainNetpbm,
+		})
+	case ".tif", ".tiff":
+		err = tiff.Encode(file, img, &tiff.Options{Compression: tiffCompression})
+	case ".qoi":
+		err = qoi.Encode(file, img)
+	case ".ico", ".cur":
+		err = ico.Encode(file, iconImages(img, iconSizes), &ico.Options{Cursor: ext == ".cur"})
+	case ".tga":
+		err = tga.Encode(file, img, tgaOptions)
 	default:
-		return fmt.Errorf("format non supporté: %s (utilisez .png, .jpg ou .jpeg)", ext)
+		return fmt.Errorf("format non supporté: %s (utilisez .png, .jpg, .jpeg, .tif, .qoi, .tga, .ico ou un format Netpbm)", ext)
 	}
 
 	if err != nil {
@@ -1173,6 +2340,130 @@ func saveImageEnhanced(img image.Image) error {
 	return nil
 }
 
+// askMetadataMode demande quelles métadonnées écrire dans le fichier
+func askMetadataMode(meta *metadata.Metadata) metadata.Mode {
+	if meta.Empty() {
+		return metadata.StripAll
+	}
+
+	fmt.Println()
+	fmt.Println("💡 Métadonnées de l'image (EXIF, profil ICC, XMP, textes):")
+	fmt.Println("  • 1 = Tout conserver")
+	fmt.Println("  • 2 = Tout supprimer (le profil de couleurs est conservé)")
+	fmt.Println("  • 3 = Supprimer la localisation (GPS)")
+	if meta.HasLocation() {
+		warningMessage("Cette image contient des coordonnées GPS")
+	}
+	fmt.Println()
+
+	switch readUserInput("Métadonnées (1/2/3, Entrée = 1)") {
+	case "", "1":
+		return metadata.KeepAll
+	case "2":
+		return metadata.StripAll
+	case "3":
+		return metadata.StripLocation
+	}
+	warningMessage("Option invalide, conservation de toutes les métadonnées")
+	return metadata.KeepAll
+}
+
+// askTIFFCompression demande l'algorithme de compression TIFF
+func askTIFFCompression() tiff.Compression {
+	fmt.Println()
+	fmt.Println("💡 Compressions TIFF disponibles:")
+	fmt.Println("  • 1 = Aucune (compatibilité maximale)")
+	fmt.Println("  • 2 = PackBits (rapide, gain modeste)")
+	fmt.Println("  • 3 = LZW (sans perte, très répandu)")
+	fmt.Println("  • 4 = Deflate (sans perte, meilleur taux)")
+	fmt.Println()
+
+	switch promptWithValidation("Compression TIFF", []string{"1", "2", "3", "4"}) {
+	case "1":
+		return tiff.Uncompressed
+	case "2":
+		return tiff.PackBits
+	case "3":
+		return tiff.LZW
+	case "4":
+		return tiff.Deflate
+	}
+	warningMessage("Option invalide, utilisation de LZW")
+	return tiff.LZW
+}
+
+// askTGAOptions demande la profondeur, la compression et l'origine TGA
+func askTGAOptions() *tga.Options {
+	fmt.Println()
+	fmt.Println("💡 Profondeurs TGA disponibles:")
+	fmt.Println("  • 8 = Couleurs indexées (256 couleurs)")
+	fmt.Println("  • 16 = 5 bits par canal + 1 bit d'alpha")
+	fmt.Println("  • 24 = Couleurs vraies, sans alpha")
+	fmt.Println("  • 32 = Couleurs vraies avec alpha")
+	fmt.Println()
+
+	opts := &tga.Options{}
+	depthStr := readUserInput("Profondeur (8/16/24/32, Entrée = automatique)")
+	if depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil || (depth != 8 && depth != 16 && depth != 24 && depth != 32) {
+			warningMessage("Profondeur invalide, choix automatique")
+		} else {
+			opts.Depth = depth
+		}
+	}
+	opts.RLE = confirmAction("Compresser en RLE ?")
+	opts.TopLeft = confirmAction("Origine en haut à gauche (au lieu du bas à gauche) ?")
+	return opts
+}
+
+// askIconSizes demande les tailles à inclure dans une icône
+func askIconSizes() []int {
+	input := readUserInput("Tailles de l'icône séparées par des virgules (Entrée = 16,32,48,64,128,256)")
+	if input == "" {
+		return ico.Sizes
+	}
+
+	var sizes []int
+	for _, part := range strings.Split(input, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size < 1 || size > 256 {
+			warningMessage("Tailles invalides (1 à 256), utilisation des tailles par défaut")
+			return ico.Sizes
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// iconImages redimensionne l'image pour chaque taille, centrée sur fond transparent
+func iconImages(img image.Image, sizes []int) []image.Image {
+	bounds := img.Bounds()
+	var icons []image.Image
+	for _, size := range sizes {
+		// Conservation du ratio dans un carré
+		w, h := size, size
+		if bounds.Dx() > bounds.Dy() {
+			h = size * bounds.Dy() / bounds.Dx()
+		} else if bounds.Dy() > bounds.Dx() {
+			w = size * bounds.Dx() / bounds.Dy()
+		}
+		if w < 1 {
+			w = 1
+		}
+		if h < 1 {
+			h = 1
+		}
+
+		resized := resizeImage(img, w, h)
+		canvas := image.NewNRGBA(image.Rect(0, 0, size, size))
+		offset := image.Pt((size-w)/2, (size-h)/2)
+		draw.Draw(canvas, resized.Bounds().Add(offset), resized, image.Point{}, draw.Src)
+		icons = append(icons, canvas)
+	}
+	return icons
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -1181,38 +2472,45 @@ func min(a, b int) int {
 }
 
 func convertImageEnhanced(img image.Image) (image.Image, error) {
-	clearScreen()
 	formatItems := []string{
 		"PNG",
 		"JPEG (qualité standard)",
 		"JPEG (haute qualité)",
 		"JPEG (qualité personnalisée)",
 		"GIF",
+		"QOI (sans perte, rapide)",
+		"Icône ICO multi-tailles (favicon)",
+		"TGA (Truevision)",
+		"Convertir les couleurs en sRGB (profil ICC)",
 		"Redimensionner l'image",
 		"Afficher les métadonnées",
 		"Retour",
 	}
 
-	drawBox("Conversion d'image", []string{
-		"Choisissez le format vers lequel vous souhaitez convertir l'image",
-		"ou une autre opération",
-	}, 70)
-	fmt.Println()
-
-	drawMenu("Options disponibles", formatItems, []string{}, []string{}, -1, 50)
-
-	choice := readUserInput("Choisissez une option")
+	choice := selectMenu(func(selected int) {
+		clearScreen()
+		drawBox("Conversion d'image", []string{
+			"Choisissez le format vers lequel vous souhaitez convertir l'image",
+			"ou une autre opération",
+		}, 70)
+		fmt.Println()
+		drawMenu("Options disponibles", formatItems, []string{}, []string{}, selected, 50)
+	}, len(formatItems), nil, "Choisissez une option", nil, "12")
 
-	if choice == "8" || choice == "0" {
+	if choice == "12" || choice == "0" {
 		return img, nil
 	}
 
-	if choice == "7" {
+	if choice == "11" {
 		readMetadata(img)
 		return img, nil
 	}
 
-	if choice == "6" {
+	if choice == "9" {
+		return convertToSRGB(img)
+	}
+
+	if choice == "10" {
 		clearScreen()
 		drawBox("Redimensionnement d'image", []string{
 			"Spécifiez les nouvelles dimensions de l'image",
@@ -1294,7 +2592,7 @@ func convertImageEnhanced(img image.Image) (image.Image, error) {
 		}
 		defer file.Close()
 
-		err = png.Encode(file, img)
+		err = metadata.EncodePNG(file, img, withColorProfile(nil))
 		if err != nil {
 			return nil, err
 		}
@@ -1322,7 +2620,7 @@ func convertImageEnhanced(img image.Image) (image.Image, error) {
 		}
 		defer file.Close()
 
-		err = jpeg.Encode(file, img, &jpeg.Options{Quality: 75})
+		err = metadata.EncodeJPEG(file, img, &jpeg.Options{Quality: 75}, withColorProfile(nil))
 		if err != nil {
 			return nil, err
 		}
@@ -1350,7 +2648,7 @@ func convertImageEnhanced(img image.Image) (image.Image, error) {
 		}
 		defer file.Close()
 
-		err = jpeg.Encode(file, img, &jpeg.Options{Quality: 95})
+		err = metadata.EncodeJPEG(file, img, &jpeg.Options{Quality: 95}, withColorProfile(nil))
 		if err != nil {
 			return nil, err
 		}
@@ -1393,7 +2691,7 @@ func convertImageEnhanced(img image.Image) (image.Image, error) {
 		}
 		defer file.Close()
 
-		err = jpeg.Encode(file, img, &jpeg.Options{Quality: quality})
+		err = metadata.EncodeJPEG(file, img, &jpeg.Options{Quality: quality}, withColorProfile(nil))
 		if err != nil {
 			return nil, err
 		}
@@ -1455,6 +2753,91 @@ func convertImageEnhanced(img image.Image) (image.Image, error) {
 			return nil, err
 		}
 
+	case "6":
+		if !strings.HasSuffix(strings.ToLower(outputPath), ".qoi") {
+			outputPath += ".qoi"
+		}
+
+		clearScreen()
+		infoMessage("Conversion en QOI...")
+
+		for i := 0; i <= 100; i += 5 {
+			drawProgressBar(float64(i)/100.0, 40)
+			time.Sleep(20 * time.Millisecond)
+			if i < 100 {
+				fmt.Print("\033[1A\r")
+			}
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		err = qoi.Encode(file, img)
+		if err != nil {
+			return nil, err
+		}
+
+	case "7":
+		if !strings.HasSuffix(strings.ToLower(outputPath), ".ico") {
+			outputPath += ".ico"
+		}
+
+		sizes := askIconSizes()
+
+		clearScreen()
+		infoMessage(fmt.Sprintf("Génération de l'icône (%d tailles)...", len(sizes)))
+
+		for i := 0; i <= 100; i += 5 {
+			drawProgressBar(float64(i)/100.0, 40)
+			time.Sleep(20 * time.Millisecond)
+			if i < 100 {
+				fmt.Print("\033[1A\r")
+			}
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		err = ico.Encode(file, iconImages(img, sizes), nil)
+		if err != nil {
+			return nil, err
+		}
+
+	case "8":
+		if !strings.HasSuffix(strings.ToLower(outputPath), ".tga") {
+			outputPath += ".tga"
+		}
+
+		opts := askTGAOptions()
+
+		clearScreen()
+		infoMessage("Conversion en TGA...")
+
+		for i := 0; i <= 100; i += 5 {
+			drawProgressBar(float64(i)/100.0, 40)
+			time.Sleep(20 * time.Millisecond)
+			if i < 100 {
+				fmt.Print("\033[1A\r")
+			}
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		err = tga.Encode(file, img, opts)
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("option de conversion invalide")
 	}
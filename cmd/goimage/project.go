@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nirdeo/goimage/pkg/project"
+)
+
+// saveProjectEnhanced enregistre la session (calques, original, historique)
+// dans un fichier .goimg
+func saveProjectEnhanced(p *project.Project) error {
+	clearScreen()
+	drawBox("Sauvegarder le projet", []string{
+		"Le projet conserve les calques séparés pour reprendre le travail plus tard",
+		"",
+		fmt.Sprintf("🗂️ %d calque(s), %d modification(s) dans l'historique", len(p.Document.Layers), len(p.History)),
+		"📁 Extension: " + project.Extension + " (ajoutée si absente)",
+		"⚠️ Attention: Un fichier existant sera écrasé",
+	}, 80)
+	fmt.Println()
+
+	suggestion := "projet" + project.Extension
+	if p.Source != "" {
+		base := filepath.Base(p.Source)
+		suggestion = strings.TrimSuffix(base, filepath.Ext(base)) + project.Extension
+	}
+	filePath := readUserInput(fmt.Sprintf("Chemin du projet (Entrée = %s)", suggestion))
+	if filePath == "" {
+		filePath = suggestion
+	}
+	if !isProjectFile(filePath) {
+		filePath += project.Extension
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if !confirmAction("Le projet existe déjà. L'écraser ?") {
+			infoMessage("Sauvegarde annulée")
+			return nil
+		}
+	}
+	if dir := filepath.Dir(filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("impossible de créer le dossier: %v", err)
+		}
+	}
+
+	if err := project.Save(filePath, p); err != nil {
+		return err
+	}
+	successMessage("Projet sauvegardé: " + filePath)
+	time.Sleep(1 * time.Second)
+	return nil
+}
+
+// openProjectEnhanced lit un fichier .goimg et affiche son contenu
+func openProjectEnhanced() (*project.Project, error) {
+	clearScreen()
+	drawBox("Ouvrir un projet", []string{
+		"Choisissez une méthode de sélection du projet:",
+		"",
+		"1. " + IconFolder + " Navigation interactive dans les fichiers (Recommandé)",
+		"2. " + IconTool + " Saisie manuelle du chemin",
+		"",
+		"💡 Astuce: Les projets sont marqués " + IconProject + " dans la navigation",
+	}, 80)
+
+	var filePath string
+	var err error
+	if promptWithValidation("Méthode d'ouverture", []string{"1", "2"}) == "1" {
+		if filePath, err = navigateToFile(); err != nil {
+			return nil, err
+		}
+	} else {
+		filePath = readUserInput("Chemin du projet (ex: projets/photo.goimg)")
+	}
+	if !isProjectFile(filePath) {
+		return nil, fmt.Errorf("%s n'est pas un projet %s", filepath.Base(filePath), project.Extension)
+	}
+
+	p, err := project.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := p.Document
+	lines := append(layerLines(doc), "")
+	if p.Source != "" {
+		lines = append(lines, "📄 Image d'origine: "+p.Source)
+	}
+	lines = append(lines, fmt.Sprintf("📜 Historique: %d modification(s)", len(p.History)))
+	start := 0
+	if len(p.History) > 5 {
+		start = len(p.History) - 5
+	}
+	for _, e := range p.History[start:] {
+		line := "  • " + e.Time.Format("2006-01-02 15:04") + " " + e.Action
+		if e.Detail != "" {
+			line += ": " + e.Detail
+		}
+		if e.Layer != "" {
+			line += " (" + e.Layer + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	clearScreen()
+	successMessage("Projet ouvert: " + filepath.Base(filePath))
+	drawBox("Projet", lines, 80)
+	fmt.Println()
+	showPreview(doc.Flatten(), filepath.Base(filePath))
+	readUserInput("Appuyez sur Entrée pour continuer")
+	return p, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+)
+
+// askSelection construit un masque de sélection sur l'image; nil si la
+// saisie est invalide
+func askSelection(img image.Image) *image.Alpha {
+	bounds := img.Bounds()
+	fmt.Println()
+	fmt.Println("🎯 Types de sélection:")
+	fmt.Println("  1. Rectangle")
+	fmt.Println("  2. Ellipse")
+	fmt.Println("  3. Polygone (lasso)")
+	fmt.Println("  4. Baguette magique (couleur proche d'un point)")
+	fmt.Println("  5. Plage de luminance")
+	fmt.Printf("🖼️ Dimensions de l'image: %d × %d pixels\n", bounds.Dx(), bounds.Dy())
+
+	invalid := func() *image.Alpha {
+		errorMessageWithTip("Sélection invalide", "Utilisez des nombres entiers, X,Y pour les points")
+		time.Sleep(2 * time.Second)
+		return nil
+	}
+	readInt := func(prompt string) (int, bool) {
+		v, err := strconv.Atoi(strings.TrimSpace(readUserInput(prompt)))
+		return v, err == nil && v > 0
+	}
+
+	var mask *image.Alpha
+	switch readUserInput("Type de sélection (Entrée = rectangle)") {
+	case "2":
+		fmt.Println("💡 Centre au format X,Y")
+		c, ok := readPoints(1)
+		if !ok {
+			return invalid()
+		}
+		rx, ok1 := readInt("Rayon horizontal")
+		ry, ok2 := readInt("Rayon vertical")
+		if !ok1 || !ok2 {
+			return invalid()
+		}
+		mask = effects.EllipseMask(bounds, c[0].X, c[0].Y, rx, ry)
+	case "3":
+		n, ok := readInt("Nombre de sommets (3 ou plus)")
+		if !ok || n < 3 {
+			return invalid()
+		}
+		p, ok := readPoints(n)
+		if !ok {
+			return invalid()
+		}
+		mask = effects.PolygonMask(bounds, p)
+	case "4":
+		fmt.Println("💡 Point de référence au format X,Y")
+		p, ok := readPoints(1)
+		if !ok || !p[0].In(bounds) {
+			return invalid()
+		}
+		tolerance := 10.0
+		if v, err := strconv.ParseFloat(strings.TrimSpace(readUserInput("Tolérance de 0 à 100 (Entrée = 10)")), 64); err == nil && v >= 0 && v <= 100 {
+			tolerance = v
+		}
+		distance := effects.RGBDistance
+		if readUserInput("Écart de couleur: 1 RGB, 2 Lab perceptuel (Entrée = RGB)") == "2" {
+			distance = effects.LabDistance
+		}
+		global := confirmAction("Sélectionner aussi les zones proches non reliées au point ?")
+		mask = effects.MagicWandMask(img, p[0].X, p[0].Y, tolerance, distance, effects.FourWay, global)
+	case "5":
+		fmt.Println("💡 Luminance de 0 (noir) à 255 (blanc): 0-80 ombres, 170-255 hautes lumières")
+		parts := strings.Split(readUserInput("Plage min-max (ex: 0-80)"), "-")
+		if len(parts) != 2 {
+			return invalid()
+		}
+		lo, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		hi, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err1 != nil || err2 != nil || lo > hi {
+			return invalid()
+		}
+		mask = effects.LuminanceMask(img, lo, hi)
+	default:
+		fmt.Println("💡 Coin supérieur gauche au format X,Y")
+		p, ok := readPoints(1)
+		if !ok {
+			return invalid()
+		}
+		w, ok1 := readInt("Largeur en pixels")
+		h, ok2 := readInt("Hauteur en pixels")
+		if !ok1 || !ok2 {
+			return invalid()
+		}
+		mask = effects.RectMask(bounds, image.Rect(p[0].X, p[0].Y, p[0].X+w, p[0].Y+h))
+	}
+
+	if confirmAction("Inverser la sélection ?") {
+		mask = effects.InvertMask(mask)
+	}
+	if input := readUserInput("Adoucissement du bord en pixels (Entrée = 0)"); input != "" {
+		v, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil || v < 0 {
+			warningMessage("Adoucissement invalide, bord net conservé")
+		} else {
+			mask = effects.FeatherMask(mask, v)
+		}
+	}
+	return mask
+}
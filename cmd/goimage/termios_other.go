@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func queryTerminal(query string, final byte) (string, error) {
+	return "", errors.New("requêtes terminal non supportées sur ce système")
+}
+
+func windowSize() (winSize, error) {
+	return winSize{}, errors.New("taille du terminal inconnue sur ce système")
+}
+
+func notifyResize(ch chan<- os.Signal) {}
+
+func enableRawMode() error {
+	return errors.New("mode brut non supporté sur ce système")
+}
+
+func restoreTerminal() {}
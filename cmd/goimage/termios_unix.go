@@ -0,0 +1,124 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Réglages du terminal avant le passage en mode brut, nil hors mode brut
+var (
+	rawMu    sync.Mutex
+	rawSaved *syscall.Termios
+)
+
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return nil, errno
+	}
+	return &t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(ioctlSetTermios), uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// windowSize interroge le terminal de la sortie standard (TIOCGWINSZ)
+func windowSize() (winSize, error) {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return winSize{}, errno
+	}
+	return winSize{cols: int(ws.Col), rows: int(ws.Row), width: int(ws.Xpixel), height: int(ws.Ypixel)}, nil
+}
+
+// notifyResize signale sur ch chaque redimensionnement du terminal
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
+
+// isTerminal indique si le fichier est un terminal
+func isTerminal(f *os.File) bool {
+	_, err := getTermios(f.Fd())
+	return err == nil
+}
+
+// queryTerminal envoie une requête au terminal et lit sa réponse en mode
+// brut, jusqu'au caractère final ou au bout d'une demi-seconde
+func queryTerminal(query string, final byte) (string, error) {
+	fd := os.Stdin.Fd()
+	old, err := getTermios(fd)
+	if err != nil {
+		return "", err
+	}
+	raw := *old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 0
+	raw.Cc[syscall.VTIME] = 5
+	if err := setTermios(fd, &raw); err != nil {
+		return "", err
+	}
+	defer setTermios(fd, old)
+
+	if _, err := os.Stdout.WriteString(query); err != nil {
+		return "", err
+	}
+	var resp []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 0 || err != nil {
+			break
+		}
+		resp = append(resp, buf[:n]...)
+		if resp[len(resp)-1] == final {
+			break
+		}
+	}
+	return string(resp), nil
+}
+
+// enableRawMode passe l'entrée standard en mode brut: touches lues une à
+// une, sans écho, Ctrl+C, Ctrl+S et Ctrl+Q transmis comme des touches.
+// Une lecture sans touche rend la main au bout d'un dixième de seconde
+func enableRawMode() error {
+	rawMu.Lock()
+	defer rawMu.Unlock()
+	if rawSaved != nil {
+		return nil
+	}
+	fd := os.Stdin.Fd()
+	old, err := getTermios(fd)
+	if err != nil {
+		return err
+	}
+	raw := *old
+	raw.Iflag &^= syscall.IXON | syscall.ICRNL
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 0
+	raw.Cc[syscall.VTIME] = 1
+	if err := setTermios(fd, &raw); err != nil {
+		return err
+	}
+	rawSaved = old
+	return nil
+}
+
+// restoreTerminal rétablit les réglages d'avant le mode brut; sans effet
+// hors mode brut
+func restoreTerminal() {
+	rawMu.Lock()
+	defer rawMu.Unlock()
+	if rawSaved != nil {
+		setTermios(os.Stdin.Fd(), rawSaved)
+		rawSaved = nil
+	}
+}
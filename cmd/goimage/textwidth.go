@@ -0,0 +1,183 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Largeur d'affichage du texte dans le terminal: les emoji et les
+// idéogrammes occupent deux colonnes, les accents combinants et les
+// séquences ANSI aucune
+
+// Plages de caractères affichés sur deux colonnes (East Asian Wide et
+// emoji en présentation graphique par défaut)
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE30, 0xFE4F}, {0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A}, {0x1F1E6, 0x1F1FF}, {0x1F200, 0x1F2FF}, {0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F900, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+const (
+	zeroWidthJoiner = 0x200D
+	emojiSelector   = 0xFE0F
+)
+
+// runeWidth renvoie le nombre de colonnes d'un caractère isolé
+func runeWidth(r rune) int {
+	if r < 0x20 || r == 0x7F || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wr := range wideRanges {
+		if r < wr[0] {
+			break
+		}
+		if r <= wr[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// nextCluster découpe le début de s: une séquence d'échappement ANSI
+// (largeur 0, escape vrai) ou un caractère avec ses accents combinants,
+// son sélecteur emoji et ses jonctions (ZWJ, drapeaux)
+func nextCluster(s string) (n, width int, escape bool) {
+	if s[0] == 0x1b {
+		if len(s) > 1 && s[1] == '[' {
+			for i := 2; i < len(s); i++ {
+				if s[i] >= 0x40 && s[i] <= 0x7E {
+					return i + 1, 0, true
+				}
+			}
+			return len(s), 0, true
+		}
+		return min(2, len(s)), 0, true
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	width = runeWidth(r)
+	regional := r >= 0x1F1E6 && r <= 0x1F1FF
+	for n < len(s) {
+		next, size := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case next == emojiSelector:
+			// Le sélecteur demande la présentation emoji, sur deux colonnes
+			if width == 1 {
+				width = 2
+			}
+			n += size
+		case next == zeroWidthJoiner:
+			n += size
+			if n < len(s) {
+				_, joined := utf8.DecodeRuneInString(s[n:])
+				n += joined
+			}
+		case regional && next >= 0x1F1E6 && next <= 0x1F1FF:
+			// Deux indicateurs régionaux forment un seul drapeau
+			n += size
+			regional = false
+		case unicode.In(next, unicode.Mn, unicode.Me):
+			n += size
+		default:
+			return n, width, false
+		}
+	}
+	return n, width, false
+}
+
+// displayWidth renvoie le nombre de colonnes occupées par s
+func displayWidth(s string) int {
+	width := 0
+	for len(s) > 0 {
+		n, w, _ := nextCluster(s)
+		width += w
+		s = s[n:]
+	}
+	return width
+}
+
+// truncateWidth coupe s à width colonnes en terminant par « … »; les
+// séquences ANSI sont conservées et les couleurs rétablies après la coupe
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	colored := false
+	for rest := s; len(rest) > 0; {
+		n, w, escape := nextCluster(rest)
+		if escape {
+			b.WriteString(rest[:n])
+			colored = true
+		} else if used+w <= width-1 {
+			b.WriteString(rest[:n])
+			used += w
+		} else {
+			break
+		}
+		rest = rest[n:]
+	}
+	b.WriteString("…")
+	if colored {
+		b.WriteString(ColorReset)
+	}
+	return b.String()
+}
+
+// ellipsizeMiddle raccourcit s à width colonnes en remplaçant son milieu
+// par « … », pour garder le début et l'extension d'un chemin. s ne doit
+// pas contenir de séquence ANSI
+func ellipsizeMiddle(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return truncateWidth(s, width)
+	}
+	var clusters []string
+	var widths []int
+	for rest := s; len(rest) > 0; {
+		n, w, _ := nextCluster(rest)
+		clusters = append(clusters, rest[:n])
+		widths = append(widths, w)
+		rest = rest[n:]
+	}
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	var left, right string
+	used := 0
+	for i := 0; i < len(clusters) && used+widths[i] <= head; i++ {
+		left += clusters[i]
+		used += widths[i]
+	}
+	used = 0
+	for i := len(clusters) - 1; i >= 0 && used+widths[i] <= tail; i-- {
+		right = clusters[i] + right
+		used += widths[i]
+	}
+	return left + "…" + right
+}
+
+// padWidth complète s par des espaces jusqu'à width colonnes
+func padWidth(s string, width int) string {
+	if w := displayWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/nirdeo/goimage/pkg/termgfx"
 )
 
 const (
@@ -34,6 +42,11 @@ const (
 	BgWhite  = "\033[47m"
 )
 
+const (
+	HideCursor = "\033[?25l"
+	ShowCursor = "\033[?25h"
+)
+
 const (
 	IconInfo     = "ℹ️"
 	IconSuccess  = "✅"
@@ -48,6 +61,9 @@ const (
 	IconEffect   = "✨"
 	IconShape    = "🔶"
 	IconConvert  = "🔄"
+	IconLayers   = "🗂️"
+	IconProject  = "🗃️"
+	IconOpen     = "📂"
 	IconExit     = "🚪"
 	IconHelp     = "💡"
 	IconTip      = "💡"
@@ -60,19 +76,24 @@ func clearScreen() {
 
 func showWelcomeBanner() {
 	clearScreen()
-	fmt.Println(ColorCyan + Bold + "╔════════════════════════════════════════════════════════════════════════╗" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "                    " + ColorPurple + Bold + "🎨 Bienvenue dans GoImage 🎨" + ColorReset + "                    " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "╠════════════════════════════════════════════════════════════════════════╣" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "                     " + ColorYellow + "Éditeur d'images TUI en Go" + ColorReset + "                     " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "                                                                        " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  " + ColorGreen + "Première fois ?" + ColorReset + " Voici les étapes recommandées :                  " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  1. " + IconLoad + " Chargez une image depuis le dossier 'test/' ou autre        " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  2. " + IconEffect + " Appliquez des effets (négatif, sépia, luminosité...)       " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  3. " + IconShape + " Dessinez des formes (carré, cercle)                        " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  4. " + IconSave + " Sauvegardez votre création                                 " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "                                                                        " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "║" + ColorReset + "  " + ColorBlue + "Raccourcis utiles:" + ColorReset + " 'h' = aide, 'q' = quitter, chiffres = sélection  " + ColorCyan + Bold + "║" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "╚════════════════════════════════════════════════════════════════════════╝" + ColorReset)
+	w := frameWidth(72)
+	border := ColorCyan + Bold
+	line := func(content string) {
+		fmt.Println(frameLine(border, "║", content, w))
+	}
+	fmt.Println(border + "╔" + strings.Repeat("═", w) + "╗" + ColorReset)
+	line(centerWidth(ColorPurple+Bold+"🎨 Bienvenue dans GoImage 🎨"+ColorReset, w))
+	fmt.Println(border + "╠" + strings.Repeat("═", w) + "╣" + ColorReset)
+	line(centerWidth(ColorYellow+"Éditeur d'images TUI en Go"+ColorReset, w))
+	line("")
+	line("  " + ColorGreen + "Première fois ?" + ColorReset + " Voici les étapes recommandées :")
+	line("  1. " + IconLoad + " Chargez une image depuis le dossier 'test/' ou autre")
+	line("  2. " + IconEffect + " Appliquez des effets (négatif, sépia, luminosité...)")
+	line("  3. " + IconShape + " Dessinez des formes (carré, cercle)")
+	line("  4. " + IconSave + " Sauvegardez votre création")
+	line("")
+	line("  " + ColorBlue + "Raccourcis utiles:" + ColorReset + " 'h' = aide, 'q' = quitter, chiffres = sélection")
+	fmt.Println(border + "╚" + strings.Repeat("═", w) + "╝" + ColorReset)
 	fmt.Println()
 	
 	fmt.Print(ColorYellow + "Appuyez sur Entrée pour continuer..." + ColorReset)
@@ -90,17 +111,23 @@ func showHelp(context string) {
 		title = "Aide - Menu Principal"
 		helpContent = []string{
 			"🎯 NAVIGATION:",
-			"• Tapez le numéro (1-6) pour sélectionner une option",
+			"• Tapez le numéro (1-9) pour sélectionner une option",
+			"• Ou déplacez-vous avec ↑/↓ et validez avec Entrée",
+			"• Raccourcis directs: Ctrl+O, Ctrl+E, Ctrl+S... (voir le menu)",
+			"• Échap revient au menu précédent",
 			"• Utilisez 'h' pour afficher cette aide",
 			"• Utilisez 'q' pour quitter l'application",
 			"",
 			"📋 OPTIONS DISPONIBLES:",
 			"• [1] Charger une image : Ouvre une image depuis votre disque",
-			"• [2] Appliquer un effet : Transforme l'image (négatif, sépia, etc.)",
-			"• [3] Dessiner une forme : Ajoute des formes géométriques",
+			"• [2] Appliquer un effet : Transforme le calque actif (négatif, sépia, etc.)",
+			"• [3] Dessiner une forme : Ajoute une forme sur un nouveau calque",
 			"• [4] Convertir l'image : Change le format ou redimensionne",
-			"• [5] Sauvegarder : Enregistre l'image modifiée",
-			"• [6] Quitter : Ferme l'application",
+			"• [5] Sauvegarder : Enregistre l'image, calques fusionnés",
+			"• [6] Gérer les calques : Ordre, opacité, mode de fusion, visibilité",
+			"• [7] Ouvrir un projet : Reprend une session enregistrée (.goimg)",
+			"• [8] Sauvegarder le projet : Conserve calques, original et historique",
+			"• [9] Quitter : Ferme l'application",
 			"",
 			"💡 CONSEIL:",
 			"Commencez toujours par charger une image (option 1) !",
@@ -116,6 +143,20 @@ func showHelp(context string) {
 			"• PNG (.png) - Recommandé pour les images avec transparence",
 			"• JPEG (.jpg, .jpeg) - Idéal pour les photos",
 			"• GIF (.gif) - Pour les images simples",
+			"• TIFF (.tif, .tiff) - Scanners, multi-pages, 16 bits",
+			"• QOI (.qoi) - Sans perte, encodage et décodage rapides",
+			"• ICO/CUR (.ico, .cur) - Icônes multi-tailles, choix de la taille",
+			"• TGA (.tga) - Textures de jeux, RLE, 8/16/24/32 bits",
+			"• Netpbm (.pbm, .pgm, .ppm, .pnm, .pam) - Outils scientifiques, 16 bits",
+			"",
+			"📷 PHOTOS JPEG:",
+			"• Les données EXIF (appareil, date, exposition, GPS) sont lues",
+			"• La photo est redressée selon son orientation EXIF",
+			"• Lancez 'goimage -no-auto-orient' pour désactiver le redressement",
+			"• Les métadonnées JPEG/PNG (EXIF, ICC, XMP, textes) sont conservées",
+			"  jusqu'à la sauvegarde, avec option de suppression du GPS",
+			"• Profil ICC (Adobe RGB, Display P3...) : conversion en sRGB proposée",
+			"  au chargement ou plus tard depuis le menu Convertir",
 			"",
 			"📂 EXEMPLES DE CHEMINS:",
 			"• test/test_image.png",
@@ -136,10 +177,29 @@ func showHelp(context string) {
 			"• Sépia : Effet vintage brun/doré",
 			"• Luminosité : Rend l'image plus claire/sombre",
 			"• Contraste : Augmente/diminue les différences de couleur",
+			"• Superposer une image : pose une autre image à la position X,Y",
+			"• Dégradé : linéaire, radial ou conique sur toute l'image",
+			"• Filigrane : logo ou texte à une ancre, ou en mosaïque diagonale",
 			"",
 			"⚙️ EFFETS PARAMÉTRABLES:",
 			"• Luminosité : 0.5 = sombre, 1.0 = normal, 1.5 = lumineux",
 			"• Contraste : 0.5 = faible, 1.0 = normal, 2.0 = fort",
+			"• Superposition : opacité, mode de fusion et opérateur de Porter-Duff",
+			"• Dégradé : ciel (linéaire, opacité partielle) ou vignettage",
+			"  (radial du blanc vers le noir, mode Produit)",
+			"• Filigrane : largeur en % de l'image, opacité, rotation, marge",
+			"  puis application possible à tout un dossier",
+			"",
+			"🎯 SÉLECTION:",
+			"• Chaque effet peut être limité à une sélection : rectangle, ellipse,",
+			"  polygone, baguette magique (couleur proche) ou plage de luminance",
+			"• La sélection peut être inversée et son bord adouci (en pixels)",
+			"",
+			"👁️ APERÇU AVANT/APRÈS:",
+			"• Après chaque effet, le rendu de tous les calques est comparé",
+			"• Séparation réglable (flèches ←/→, ou <, > et position en % hors",
+			"  terminal) ou côte à côte (c)",
+			"• 'a' ou Entrée accepte l'effet, 'r' le rejette",
 			"",
 			"💡 ASTUCE:",
 			"Vous pouvez appliquer plusieurs effets successivement !",
@@ -150,11 +210,44 @@ func showHelp(context string) {
 			"🔶 FORMES DISPONIBLES:",
 			"• Carré : Forme rectangulaire remplie",
 			"• Cercle : Forme circulaire remplie",
+			"• Triangle : Trois sommets au format X,Y",
+			"• Ligne : Deux extrémités au format X,Y",
+			"• Forme vectorielle : rectangle (arrondi ou non), cercle, ellipse,",
+			"  triangle, polygone régulier, étoile, flèche, polyligne, courbe de Bézier",
+			"• Polyligne recoupée : règle non nulle ou pair-impair pour le remplissage",
+			"• Texte : police 8 × 16 intégrée, accents français, plusieurs lignes",
+			"  taille, couleur, cadre de fond, alignement, coordonnées ou ancre",
+			"• Police TrueType : chemin d'un fichier .ttf, taille en points, crénage",
+			"• Pot de peinture : remplit la zone de couleur proche d'un point",
+			"  tolérance 0 à 100, écart RGB ou Lab, voisinage par côtés ou coins,",
+			"  zone reliée ou toutes les couleurs proches de l'image",
+			"",
+			"✏️ CONTOUR:",
+			"• Remplissage et contour de couleurs distinctes, chacun facultatif",
+			"• Épaisseur en pixels, centrée sur le bord de la forme",
+			"• Pointillés : longueurs trait,espace répétées (ex: 10,5)",
+			"• Extrémités nettes, arrondies ou carrées",
+			"• Angles pointus, arrondis ou biseautés",
+			"",
+			"🎭 OPACITÉ ET FUSION:",
+			"• Opacité de 0 à 100 %",
+			"• Modes : produit, superposition claire, incrustation, lumière douce,",
+			"  assombrir, éclaircir, différence",
+			"• Opérateurs de Porter-Duff : source sur destination, source dans",
+			"  destination, ou exclusif, effacement...",
+			"• Réglages appliqués au calque de la forme, fusionné à la sauvegarde",
+			"",
+			"✨ ANTICRÉNELAGE:",
+			"• Adoucit les bords selon la part de chaque pixel couverte",
+			"• Proposé pour le cercle, le triangle et la ligne",
 			"",
 			"🎨 COULEURS:",
 			"• Format RGB : R,G,B (ex: 255,0,0 pour rouge)",
 			"• Valeurs entre 0 et 255 pour chaque composante",
 			"• Exemples : 0,255,0 (vert), 0,0,255 (bleu)",
+			"• d : dégradé linéaire, radial ou conique à la place d'une couleur",
+			"  couleurs séparées par ; et position facultative (0,0,255@30)",
+			"  au-delà des bornes : étendre, répéter ou refléter",
 			"",
 			"📐 POSITIONNEMENT:",
 			"• X=0 : bord gauche de l'image",
@@ -164,6 +257,27 @@ func showHelp(context string) {
 			"💡 CONSEIL:",
 			"Commencez par de petites formes pour tester !",
 		}
+	case "layers":
+		title = "Aide - Calques"
+		helpContent = []string{
+			"🗂️ PRINCIPE:",
+			"• L'image chargée devient le calque « Arrière-plan »",
+			"• Les effets ne modifient que le calque actif (▶)",
+			"• Chaque forme ou texte est dessiné sur un nouveau calque,",
+			"  placé au-dessus du calque actif",
+			"• Le pot de peinture compare les couleurs de tous les calques visibles",
+			"",
+			"⚙️ PROPRIÉTÉS D'UN CALQUE:",
+			"• Opacité de 0 à 100 %",
+			"• Mode de fusion : produit, superposition claire, incrustation...",
+			"• Décalage X,Y de son coin supérieur gauche",
+			"• Visibilité : un calque masqué n'apparaît pas dans le rendu",
+			"",
+			"💾 SAUVEGARDE ET CONVERSION:",
+			"• Les calques visibles sont fusionnés dans le fichier enregistré",
+			"• Redimensionner ou convertir en sRGB fusionne les calques",
+			"• « Aplatir l'image » fusionne les calques sans enregistrer",
+		}
 	default:
 		title = "Aide générale"
 		helpContent = []string{
@@ -186,43 +300,54 @@ func showHelp(context string) {
 	fmt.Scanln()
 }
 
+// drawBox encadre content; la largeur demandée est réduite pour tenir dans
+// le terminal et les lignes trop longues sont tronquées par « … »
 func drawBox(title string, content []string, width int) {
-	if width < len(title)+4 {
-		width = len(title) + 4
-	}
+	cols, _ := terminalSize()
+	width = max(16, min(width, cols-4))
+	title = truncateWidth(title, width-4)
+	titleWidth := displayWidth(title)
 
 	fmt.Print(ColorCyan + Bold)
 	fmt.Print("╭")
-	titlePadding := width - len(title) - 4
+	// Les bordures couvrent aussi les marges d'une colonne autour du texte
+	titlePadding := width + 2 - titleWidth - 4
 	leftPadding := titlePadding / 2
 	rightPadding := titlePadding - leftPadding
 	
-	for i := 0; i < leftPadding; i++ {
-		fmt.Print("─")
-	}
+	fmt.Print(strings.Repeat("─", leftPadding))
 	fmt.Print("┤ " + ColorPurple + title + ColorCyan + " ├")
-	for i := 0; i < rightPadding; i++ {
-		fmt.Print("─")
-	}
+	fmt.Print(strings.Repeat("─", rightPadding))
 	fmt.Println("╮" + ColorReset)
 
 	for _, line := range content {
-		paddedLine := line
-		if len(line) < width {
-			paddedLine = line + strings.Repeat(" ", width-len(line))
-		} else if len(line) > width {
-			paddedLine = line[:width-3] + "..."
-		}
+		paddedLine := padWidth(truncateWidth(line, width), width)
 		fmt.Println(ColorCyan + Bold + "│" + ColorReset + " " + paddedLine + " " + ColorCyan + Bold + "│" + ColorReset)
 	}
 
 	fmt.Print(ColorCyan + Bold + "╰")
-	for i := 0; i < width; i++ {
-		fmt.Print("─")
-	}
+	fmt.Print(strings.Repeat("─", width+2))
 	fmt.Println("╯" + ColorReset)
 }
 
+// frameWidth réduit la largeur intérieure d'un cadre pour qu'il tienne,
+// bordures comprises, dans le terminal
+func frameWidth(width int) int {
+	cols, _ := terminalSize()
+	return max(20, min(width, cols-2))
+}
+
+// frameLine place content entre deux bords edge, complété ou tronqué à
+// width colonnes
+func frameLine(border, edge, content string, width int) string {
+	return border + edge + ColorReset + padWidth(truncateWidth(content, width), width) + border + edge + ColorReset
+}
+
+// centerWidth centre s sur width colonnes
+func centerWidth(s string, width int) string {
+	return strings.Repeat(" ", max(0, (width-displayWidth(s))/2)) + s
+}
+
 func drawMenuItem(index int, icon string, text string, shortcut string, selected bool) string {
 	shortcutText := ""
 	if shortcut != "" {
@@ -328,35 +453,41 @@ func drawProgressBar(progress float64, width int) {
 
 func drawHeader() {
 	clearScreen()
-	fmt.Println(ColorCyan + Bold + "╭──────────────────────────────────────────────────────────────────────╮" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "│" + ColorReset + "                    " + ColorPurple + Bold + "🎨 GoImage - Éditeur d'Images 🎨" + ColorReset + "                    " + ColorCyan + Bold + "│" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "│" + ColorReset + "                      " + ColorYellow + "Interface TUI - Version 1.0" + ColorReset + "                      " + ColorCyan + Bold + "│" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "╰──────────────────────────────────────────────────────────────────────╯" + ColorReset)
+	w := frameWidth(70)
+	border := ColorCyan + Bold
+	fmt.Println(border + "╭" + strings.Repeat("─", w) + "╮" + ColorReset)
+	fmt.Println(frameLine(border, "│", centerWidth(ColorPurple+Bold+"🎨 GoImage - Éditeur d'Images 🎨"+ColorReset, w), w))
+	fmt.Println(frameLine(border, "│", centerWidth(ColorYellow+"Interface TUI - Version 1.0"+ColorReset, w), w))
+	fmt.Println(border + "╰" + strings.Repeat("─", w) + "╯" + ColorReset)
 	fmt.Println()
 }
 
 func drawFooter() {
+	w := frameWidth(70)
+	border := ColorCyan + Bold
 	fmt.Println()
-	fmt.Println(ColorCyan + Bold + "╭──────────────────────────────────────────────────────────────────────╮" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "│" + ColorReset + " " + ColorGreen + IconKey + " Raccourcis:" + ColorReset + " " + ColorYellow + "q" + ColorReset + "=quitter " + ColorYellow + "h" + ColorReset + "=aide " + ColorYellow + "1-6" + ColorReset + "=sélection " + ColorYellow + "Entrée" + ColorReset + "=confirmer" + ColorCyan + "     │" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "│" + ColorReset + " " + ColorBlue + IconTip + " Astuce:" + ColorReset + " Suivez l'ordre logique: Charger → Modifier → Sauvegarder" + ColorCyan + "    │" + ColorReset)
-	fmt.Println(ColorCyan + Bold + "╰──────────────────────────────────────────────────────────────────────╯" + ColorReset)
+	fmt.Println(border + "╭" + strings.Repeat("─", w) + "╮" + ColorReset)
+	fmt.Println(frameLine(border, "│", " "+ColorGreen+IconKey+" Raccourcis:"+ColorReset+" "+ColorYellow+"↑↓"+ColorReset+"=naviguer "+ColorYellow+"1-9"+ColorReset+"=choisir "+ColorYellow+"Échap"+ColorReset+"=retour "+ColorYellow+"h"+ColorReset+"=aide "+ColorYellow+"q"+ColorReset+"=quitter", w))
+	fmt.Println(frameLine(border, "│", " "+ColorBlue+IconTip+" Astuce:"+ColorReset+" Suivez l'ordre logique: Charger → Modifier → Sauvegarder", w))
+	fmt.Println(border + "╰" + strings.Repeat("─", w) + "╯" + ColorReset)
 }
 
 func drawStatusBar(currentImage string, hasImage bool) {
-	status := "Aucune image chargée"
+	w := frameWidth(64)
 	icon := IconError
 	color := ColorRed
+	status := "Aucune image chargée"
 	
 	if hasImage {
-		status = "Image: " + currentImage
 		icon = IconSuccess
 		color = ColorGreen
+		// Le chemin est raccourci par le milieu pour garder le nom du fichier
+		status = "Image: " + ellipsizeMiddle(currentImage, w-displayWidth(icon)-10)
 	}
 	
-	fmt.Println(ColorCyan + "╭─ " + ColorYellow + "STATUT" + ColorCyan + " ─────────────────────────────────────────────────────────────╮" + ColorReset)
-	fmt.Println(ColorCyan + "│" + ColorReset + " " + color + icon + " " + status + strings.Repeat(" ", 62-len(status)) + ColorCyan + "│" + ColorReset)
-	fmt.Println(ColorCyan + "╰────────────────────────────────────────────────────────────────╯" + ColorReset)
+	fmt.Println(ColorCyan + "╭─ " + ColorYellow + "STATUT" + ColorCyan + " " + strings.Repeat("─", w-9) + "╮" + ColorReset)
+	fmt.Println(frameLine(ColorCyan, "│", " "+color+icon+" "+status+ColorReset, w))
+	fmt.Println(ColorCyan + "╰" + strings.Repeat("─", w) + "╯" + ColorReset)
 	fmt.Println()
 }
 
@@ -375,17 +506,404 @@ func showNotification(message string, duration time.Duration, isSuccess bool) {
 
 func confirmAction(message string) bool {
 	fmt.Print(ColorYellow + IconQuestion + " " + message + " (o/N) " + ColorReset)
+	if answer, ok := readConfirmKey(); ok {
+		return answer
+	}
 	var input string
 	fmt.Scanln(&input)
 	return strings.ToLower(input) == "o" || strings.ToLower(input) == "oui"
 }
 
 func displayImageInfo(width, height int, format string) {
-	fmt.Println(ColorBlue + "╭─ " + ColorYellow + "INFORMATIONS IMAGE" + ColorBlue + " ─────────────────────────────────────────╮" + ColorReset)
-	fmt.Println(ColorBlue + "│" + ColorReset + " " + IconImage + " Dimensions: " + ColorGreen + fmt.Sprintf("%d × %d pixels", width, height) + ColorReset + strings.Repeat(" ", 35-len(fmt.Sprintf("%d × %d pixels", width, height))) + ColorBlue + "│" + ColorReset)
+	w := frameWidth(66)
+	fmt.Println(ColorBlue + "╭─ " + ColorYellow + "INFORMATIONS IMAGE" + ColorBlue + " " + strings.Repeat("─", w-21) + "╮" + ColorReset)
+	fmt.Println(frameLine(ColorBlue, "│", " "+IconImage+" Dimensions: "+ColorGreen+fmt.Sprintf("%d × %d pixels", width, height)+ColorReset, w))
 	if format != "" {
-		fmt.Println(ColorBlue + "│" + ColorReset + " " + IconTool + " Format: " + ColorGreen + format + ColorReset + strings.Repeat(" ", 47-len(format)) + ColorBlue + "│" + ColorReset)
+		fmt.Println(frameLine(ColorBlue, "│", " "+IconTool+" Format: "+ColorGreen+format+ColorReset, w))
 	}
-	fmt.Println(ColorBlue + "╰──────────────────────────────────────────────────────────────────╯" + ColorReset)
+	fmt.Println(ColorBlue + "╰" + strings.Repeat("─", w) + "╯" + ColorReset)
 	fmt.Println()
 }
+
+// PreviewMode est la technique d'affichage de l'aperçu dans le terminal
+type PreviewMode int
+
+const (
+	// PreviewTrueColor dessine des demi-blocs en couleurs 24 bits
+	PreviewTrueColor PreviewMode = iota
+	// Preview256 dessine des demi-blocs avec la palette 256 couleurs
+	Preview256
+	// PreviewASCII dessine des caractères en niveaux de gris, sans couleur
+	PreviewASCII
+	// PreviewSixel envoie une image Sixel au pixel près
+	PreviewSixel
+	// PreviewKitty utilise le protocole graphique Kitty
+	PreviewKitty
+)
+
+// Noms des modes pour le réglage manuel (-preview ou GOIMAGE_PREVIEW)
+var previewModeNames = map[string]PreviewMode{
+	"truecolor": PreviewTrueColor,
+	"256":       Preview256,
+	"ascii":     PreviewASCII,
+	"sixel":     PreviewSixel,
+	"kitty":     PreviewKitty,
+}
+
+// Caractères de l'aperçu ASCII, du plus sombre au plus clair
+const asciiRamp = " .:-=+*#%@"
+
+// Taille supposée d'une cellule du terminal en pixels pour Sixel et Kitty,
+// quand le terminal ne donne pas sa taille en pixels
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// previewSetting force un mode d'aperçu ("auto" pour la détection)
+var previewSetting = "auto"
+
+// Mode détecté une seule fois par session
+var (
+	previewDetected bool
+	previewMode     PreviewMode
+)
+
+// detectPreviewMode renvoie le mode réglé manuellement, sinon le détecte
+// d'après l'environnement puis en interrogeant le terminal
+func detectPreviewMode() PreviewMode {
+	if mode, ok := previewModeNames[strings.ToLower(previewSetting)]; ok {
+		return mode
+	}
+	if !previewDetected {
+		previewMode = detectTerminalGraphics()
+		previewDetected = true
+	}
+	return previewMode
+}
+
+func detectTerminalGraphics() PreviewMode {
+	term := os.Getenv("TERM")
+	if os.Getenv("NO_COLOR") != "" || term == "dumb" {
+		return PreviewASCII
+	}
+
+	// Terminaux reconnus par leurs variables d'environnement
+	program := os.Getenv("TERM_PROGRAM")
+	if os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty" || program == "WezTerm" {
+		return PreviewKitty
+	}
+	if strings.Contains(term, "sixel") || term == "mlterm" || strings.HasPrefix(term, "foot") {
+		return PreviewSixel
+	}
+
+	// Requête graphique Kitty suivie d'une demande d'attributs (DA1): le
+	// terminal répond toujours à la seconde, l'attribut 4 signale Sixel
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		resp, err := queryTerminal("\033_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\033\\\033[c", 'c')
+		if err == nil {
+			if strings.Contains(resp, "_Gi=31;OK") {
+				return PreviewKitty
+			}
+			if i := strings.Index(resp, "[?"); i >= 0 {
+				attrs := strings.Split(strings.TrimSuffix(resp[i+2:], "c"), ";")
+				for _, a := range attrs[1:] {
+					if a == "4" {
+						return PreviewSixel
+					}
+				}
+			}
+		}
+	}
+
+	colorterm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorterm == "truecolor" || colorterm == "24bit" || os.Getenv("WT_SESSION") != "" {
+		return PreviewTrueColor
+	}
+	return Preview256
+}
+
+// winSize est la taille du terminal en cellules et, si le terminal la
+// fournit, en pixels (0 sinon)
+type winSize struct {
+	cols, rows    int
+	width, height int
+}
+
+// cellSize renvoie la taille d'une cellule en pixels, déduite de la taille
+// de la fenêtre, sinon cellWidth × cellHeight
+func cellSize() (int, int) {
+	if ws, err := windowSize(); err == nil && ws.cols > 0 && ws.rows > 0 {
+		if w, h := ws.width/ws.cols, ws.height/ws.rows; w > 0 && h > 0 {
+			return w, h
+		}
+	}
+	return cellWidth, cellHeight
+}
+
+// terminalSize renvoie la taille du terminal en colonnes et lignes, lue
+// à chaque appel pour suivre les redimensionnements. Hors terminal, les
+// variables COLUMNS et LINES, sinon 80 × 24, servent de repli
+func terminalSize() (int, int) {
+	if ws, err := windowSize(); err == nil && ws.cols > 0 && ws.rows > 0 {
+		return ws.cols, ws.rows
+	}
+	cols, rows := 80, 24
+	if v, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && v > 0 {
+		cols = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LINES")); err == nil && v > 0 {
+		rows = v
+	}
+	return cols, rows
+}
+
+// showPreview affiche l'image réduite à la taille du terminal
+func showPreview(img image.Image, title string) {
+	// Place réservée au titre et à l'invite
+	drawPreview(img, title, 4)
+}
+
+// drawPreview affiche l'aperçu en laissant reserved lignes libres dessous
+func drawPreview(img image.Image, title string, reserved int) {
+	cols, rows := terminalSize()
+	rows = max(1, rows-reserved)
+	mode := detectPreviewMode()
+	if mode == PreviewSixel || mode == PreviewKitty {
+		fmt.Println(renderGraphics(img, cols-2, rows, mode))
+	} else {
+		for _, line := range renderPreview(img, cols-2, rows, mode) {
+			fmt.Println(" " + line)
+		}
+	}
+	bounds := img.Bounds()
+	fmt.Println(ColorDim + fmt.Sprintf(" %s (%d × %d)", title, bounds.Dx(), bounds.Dy()) + ColorReset)
+}
+
+// renderPreview réduit l'image pour tenir dans cols × rows cellules et
+// renvoie les lignes à afficher
+func renderPreview(img image.Image, cols, rows int, mode PreviewMode) []string {
+	bounds := img.Bounds()
+	if bounds.Empty() || cols < 1 || rows < 1 {
+		return nil
+	}
+
+	// Une cellule vaut deux pixels carrés en demi-blocs, un pixel deux fois
+	// plus haut que large en ASCII
+	maxW, maxH := float64(cols), float64(rows*2)
+	if mode == PreviewASCII {
+		maxH = float64(rows)
+	}
+	aspect := float64(bounds.Dy()) / float64(bounds.Dx())
+	if mode == PreviewASCII {
+		aspect /= 2
+	}
+	w, h := maxW, maxW*aspect
+	if h > maxH {
+		w, h = maxH/aspect, maxH
+	}
+	pw, ph := max(1, int(w+0.5)), max(1, int(h+0.5))
+	pixels := downsample(img, pw, ph, 2)
+
+	var lines []string
+	if mode == PreviewASCII {
+		for y := 0; y < ph; y++ {
+			var sb strings.Builder
+			for x := 0; x < pw; x++ {
+				c := pixels[y*pw+x]
+				lum := (299*int(c[0]) + 587*int(c[1]) + 114*int(c[2])) / 1000
+				sb.WriteByte(asciiRamp[lum*(len(asciiRamp)-1)/255])
+			}
+			lines = append(lines, sb.String())
+		}
+		return lines
+	}
+
+	// Demi-bloc supérieur: couleur de texte en haut, fond en bas
+	for y := 0; y < ph; y += 2 {
+		var sb strings.Builder
+		for x := 0; x < pw; x++ {
+			top := pixels[y*pw+x]
+			sb.WriteString(ansiColor(top, false, mode))
+			if y+1 < ph {
+				sb.WriteString(ansiColor(pixels[(y+1)*pw+x], true, mode))
+			} else {
+				sb.WriteString("\033[49m")
+			}
+			sb.WriteString("▀")
+		}
+		sb.WriteString(ColorReset)
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// renderGraphics renvoie la séquence Sixel ou Kitty de l'image réduite à
+// cols × rows cellules
+func renderGraphics(img image.Image, cols, rows int, mode PreviewMode) string {
+	bounds := img.Bounds()
+	if bounds.Empty() || cols < 1 || rows < 1 {
+		return ""
+	}
+	cw, ch := cellSize()
+	maxW, maxH := float64(cols*cw), float64(rows*ch)
+	scale := math.Min(maxW/float64(bounds.Dx()), maxH/float64(bounds.Dy()))
+	// Pas d'agrandissement: l'aperçu reste au pixel près
+	if scale > 1 {
+		scale = 1
+	}
+	pw := max(1, int(float64(bounds.Dx())*scale+0.5))
+	ph := max(1, int(float64(bounds.Dy())*scale+0.5))
+
+	pixels := downsample(img, pw, ph, 16)
+	small := image.NewRGBA(image.Rect(0, 0, pw, ph))
+	for i, c := range pixels {
+		small.SetRGBA(i%pw, i/pw, color.RGBA{c[0], c[1], c[2], 255})
+	}
+
+	var buf bytes.Buffer
+	if mode == PreviewKitty {
+		termgfx.EncodeKitty(&buf, small)
+	} else {
+		termgfx.EncodeSixel(&buf, small, 256)
+	}
+	return buf.String()
+}
+
+// downsample moyenne les pixels source couverts par chaque pixel cible; la
+// transparence est rendue sur un damier de cases de checker pixels
+func downsample(img image.Image, w, h, checker int) [][3]uint8 {
+	bounds := img.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	out := make([][3]uint8, w*h)
+
+	for ty := 0; ty < h; ty++ {
+		y0 := ty * sh / h
+		y1 := max(y0+1, (ty+1)*sh/h)
+		for tx := 0; tx < w; tx++ {
+			x0 := tx * sw / w
+			x1 := max(x0+1, (tx+1)*sw/w)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			// Couleurs prémultipliées: on complète avec le fond du damier
+			bg := uint64(0x66)
+			if (tx/checker+ty/checker)%2 == 1 {
+				bg = 0x99
+			}
+			back := bg * (0xffff - a/n) / 0xffff
+			out[ty*w+tx] = [3]uint8{
+				uint8(r/n>>8 + back),
+				uint8(g/n>>8 + back),
+				uint8(b/n>>8 + back),
+			}
+		}
+	}
+	return out
+}
+
+// ansiColor renvoie la séquence de couleur de texte ou de fond
+func ansiColor(c [3]uint8, background bool, mode PreviewMode) string {
+	layer := 38
+	if background {
+		layer = 48
+	}
+	if mode == PreviewTrueColor {
+		return fmt.Sprintf("\033[%d;2;%d;%d;%dm", layer, c[0], c[1], c[2])
+	}
+	return fmt.Sprintf("\033[%d;5;%dm", layer, color256(c))
+}
+
+// Niveaux du cube 6 × 6 × 6 de la palette 256 couleurs
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// color256 choisit l'entrée la plus proche dans le cube ou la rampe de gris
+func color256(c [3]uint8) int {
+	nearest := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := int(c[0]), int(c[1]), int(c[2])
+	ri, gi, bi := nearest(r), nearest(g), nearest(b)
+	cubeDist := sq(r-cubeLevels[ri]) + sq(g-cubeLevels[gi]) + sq(b-cubeLevels[bi])
+
+	// Rampe de gris 232-255: 8, 18, ..., 238
+	gray := (r + g + b) / 3
+	gi2 := min(23, max(0, (gray-3)/10))
+	level := 8 + gi2*10
+	grayDist := sq(r-level) + sq(g-level) + sq(b-level)
+
+	if grayDist < cubeDist {
+		return 232 + gi2
+	}
+	return 16 + 36*ri + 6*gi + bi
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sq(v int) int {
+	return v * v
+}
+
+// splitView montre l'image avant à gauche de la position (0 à 1) et
+// l'image après à droite, séparées par un trait blanc
+func splitView(before, after image.Image, pos float64) image.Image {
+	bounds := after.Bounds()
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	split := int(pos * float64(bounds.Dx()))
+	line := max(1, bounds.Dx()/200)
+	bb := before.Bounds()
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			switch {
+			case x >= split-line/2 && x < split-line/2+line:
+				result.Set(x, y, color.White)
+			case x < split && x < bb.Dx() && y < bb.Dy():
+				result.Set(x, y, before.At(bb.Min.X+x, bb.Min.Y+y))
+			default:
+				result.Set(x, y, after.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+	}
+	return result
+}
+
+// sideBySide place les deux images côte à côte avec un espace entre elles
+func sideBySide(before, after image.Image) image.Image {
+	bb, ab := before.Bounds(), after.Bounds()
+	gap := max(2, bb.Dx()/40)
+	result := image.NewRGBA(image.Rect(0, 0, bb.Dx()+gap+ab.Dx(), max(bb.Dy(), ab.Dy())))
+
+	for y := 0; y < bb.Dy(); y++ {
+		for x := 0; x < bb.Dx(); x++ {
+			result.Set(x, y, before.At(bb.Min.X+x, bb.Min.Y+y))
+		}
+	}
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			result.Set(bb.Dx()+gap+x, y, after.At(ab.Min.X+x, ab.Min.Y+y))
+		}
+	}
+	return result
+}
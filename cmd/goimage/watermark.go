@@ -0,0 +1,367 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+	"github.com/nirdeo/goimage/pkg/ico"
+	"github.com/nirdeo/goimage/pkg/layers"
+	"github.com/nirdeo/goimage/pkg/metadata"
+	"github.com/nirdeo/goimage/pkg/netpbm"
+	"github.com/nirdeo/goimage/pkg/qoi"
+	"github.com/nirdeo/goimage/pkg/tga"
+	"github.com/nirdeo/goimage/pkg/tiff"
+	"github.com/nirdeo/goimage/pkg/truetype"
+)
+
+// Noms des ancres en ligne de commande
+var anchorFlags = map[string]effects.Anchor{
+	"top-left":     effects.AnchorTopLeft,
+	"top":          effects.AnchorTop,
+	"top-right":    effects.AnchorTopRight,
+	"left":         effects.AnchorLeft,
+	"center":       effects.AnchorCenter,
+	"right":        effects.AnchorRight,
+	"bottom-left":  effects.AnchorBottomLeft,
+	"bottom":       effects.AnchorBottom,
+	"bottom-right": effects.AnchorBottomRight,
+}
+
+// askWatermark configure un filigrane de façon interactive
+func askWatermark() (*effects.WatermarkEffect, bool) {
+	wm := &effects.WatermarkEffect{Anchor: effects.AnchorBottomRight, Opacity: 0.5, Scale: 0.2}
+
+	fmt.Println("💧 Motif du filigrane:")
+	fmt.Println("  1. Logo (image)")
+	fmt.Println("  2. Texte")
+	switch readUserInput("Motif (1 ou 2)") {
+	case "1":
+		infoMessage("Sélectionnez le logo")
+		path, err := navigateToFile()
+		if err != nil {
+			return nil, false
+		}
+		if wm.Mark, err = decodeImageFile(path); err != nil {
+			errorMessage(err.Error())
+			return nil, false
+		}
+	case "2":
+		text := readUserInput("Texte du filigrane (ex: © Mon Studio)")
+		if text == "" {
+			return nil, false
+		}
+		var font *truetype.Font
+		if path := readUserInput("Police TrueType (chemin .ttf, Entrée = police intégrée)"); path != "" {
+			f, err := truetype.Open(path)
+			if err != nil {
+				warningMessage(fmt.Sprintf("Police illisible (%v), utilisation de la police intégrée", err))
+			} else {
+				font = f
+			}
+		}
+		c, ok := parseRGB(readUserInput("Couleur R,G,B (Entrée = blanc)"))
+		if !ok {
+			c = color.RGBA{255, 255, 255, 255}
+		}
+		// Rendu assez grand pour rester net une fois mis à l'échelle
+		wm.Mark = effects.TextMark(text, font, 96, 4, c)
+	default:
+		return nil, false
+	}
+
+	if v, err := strconv.ParseFloat(readUserInput("Largeur en % de celle de l'image (Entrée = 20)"), 64); err == nil && v > 0 && v <= 100 {
+		wm.Scale = v / 100
+	}
+	if v, err := strconv.ParseFloat(readUserInput("Opacité en % (Entrée = 50)"), 64); err == nil && v >= 0 && v <= 100 {
+		wm.Opacity = v / 100
+	}
+
+	wm.Tiled = confirmAction("Mosaïque diagonale sur toute l'image ?")
+	if wm.Tiled {
+		wm.Rotation = 30
+		wm.Scale /= 2
+		wm.Spacing = 40
+	}
+	if v, err := strconv.ParseFloat(readUserInput(fmt.Sprintf("Rotation en degrés, sens antihoraire (Entrée = %g)", wm.Rotation)), 64); err == nil {
+		wm.Rotation = v
+	}
+	if wm.Tiled {
+		if v, err := strconv.Atoi(readUserInput("Espacement entre les motifs en pixels (Entrée = 40)")); err == nil && v >= 0 {
+			wm.Spacing = v
+		}
+		return wm, true
+	}
+
+	fmt.Println("📍 Position:")
+	for a := effects.AnchorTopLeft; a <= effects.AnchorBottomRight; a++ {
+		fmt.Printf("  %d. %s\n", int(a), a)
+	}
+	if v, err := strconv.Atoi(readUserInput("Position (Entrée = bas droite)")); err == nil && v >= 1 && v <= int(effects.AnchorBottomRight) {
+		wm.Anchor = effects.Anchor(v)
+	}
+	wm.Margin = 20
+	if v, err := strconv.Atoi(readUserInput("Marge aux bords en pixels (Entrée = 20)")); err == nil && v >= 0 {
+		wm.Margin = v
+	}
+	return wm, true
+}
+
+// watermarkFolder applique le filigrane à toutes les images du dossier et
+// les enregistre dans outDir sous le même nom et au même format
+func watermarkFolder(dir, outDir string, wm *effects.WatermarkEffect, report func(name string, err error)) (int, int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		report(dir, err)
+		return 0, 1
+	}
+	if mustAbs(dir) == mustAbs(outDir) {
+		report(outDir, fmt.Errorf("le dossier de sortie doit différer du dossier source"))
+		return 0, 1
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		report(outDir, err)
+		return 0, 1
+	}
+
+	done, failed := 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || !isImageFile(entry.Name()) {
+			continue
+		}
+		err := watermarkFile(filepath.Join(dir, entry.Name()), filepath.Join(outDir, entry.Name()), wm)
+		report(entry.Name(), err)
+		if err != nil {
+			failed++
+		} else {
+			done++
+		}
+	}
+	return done, failed
+}
+
+func mustAbs(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
+// watermarkFile traite une image: les métadonnées JPEG/PNG sont conservées
+// et la photo redressée comme au chargement
+func watermarkFile(src, dst string, wm *effects.WatermarkEffect) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, format, err := image.Decode(file)
+	if err == image.ErrFormat && strings.EqualFold(filepath.Ext(src), ".tga") {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			img, err = tga.Decode(file)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("décodage impossible: %v", err)
+	}
+	meta := readFileMetadata(file, format)
+	if meta != nil && len(meta.Exif) > 0 {
+		img = applyExifOrientation(meta, img)
+	}
+	return encodeImageFile(dst, wm.Apply(img), meta)
+}
+
+// encodeImageFile enregistre l'image selon l'extension, avec les options par
+// défaut de chaque format
+func encodeImageFile(path string, img image.Image, meta *metadata.Metadata) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".png":
+		err = metadata.EncodePNG(file, img, meta)
+	case ".jpg", ".jpeg":
+		err = metadata.EncodeJPEG(file, img, &jpeg.Options{Quality: 90}, meta)
+	case ".gif":
+		err = gif.Encode(file, img, &gif.Options{NumColors: 256})
+	case ".pbm", ".pgm", ".ppm", ".pnm", ".pam":
+		err = netpbm.Encode(file, img, &netpbm.Options{Format: netpbm.FormatFromExt(ext)})
+	case ".tif", ".tiff":
+		err = tiff.Encode(file, img, &tiff.Options{Compression: tiff.Uncompressed})
+	case ".qoi":
+		err = qoi.Encode(file, img)
+	case ".ico", ".cur":
+		err = ico.Encode(file, iconImages(img, ico.Sizes), &ico.Options{Cursor: ext == ".cur"})
+	case ".tga":
+		err = tga.Encode(file, img, nil)
+	default:
+		err = fmt.Errorf("format non supporté: %s", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("encodage impossible: %v", err)
+	}
+	return nil
+}
+
+// watermarkEnhanced applique un filigrane au calque actif de doc, puis propose
+// de traiter un dossier entier avec les mêmes réglages
+func watermarkEnhanced(doc *layers.Document) (image.Image, string) {
+	img := doc.ActiveLayer().Image
+	clearScreen()
+	drawBox("Filigrane", []string{
+		"Logo ou texte semi-transparent, à une ancre ou en mosaïque diagonale",
+		"",
+		"💡 La taille est relative à la largeur de chaque image",
+	}, 80)
+	fmt.Println()
+
+	wm, ok := askWatermark()
+	if !ok {
+		warningMessage("Filigrane annulé")
+		time.Sleep(1 * time.Second)
+		return img, ""
+	}
+
+	result := img
+	modified := wm.Apply(img)
+	if compareBeforeAfter(doc.Flatten(), doc.FlattenWith(modified), wm.Name()) {
+		result = modified
+		successMessage("Filigrane appliqué")
+	} else {
+		infoMessage("Filigrane rejeté, l'image d'origine est conservée")
+	}
+
+	if confirmAction("Appliquer ce filigrane à toutes les images d'un dossier ?") {
+		dir := readUserInput("Dossier source (ex: export)")
+		out := readUserInput(fmt.Sprintf("Dossier de sortie (Entrée = %s)", filepath.Join(dir, "filigrane")))
+		if out == "" {
+			out = filepath.Join(dir, "filigrane")
+		}
+		done, failed := watermarkFolder(dir, out, wm, func(name string, err error) {
+			if err != nil {
+				errorMessage(fmt.Sprintf("%s: %v", name, err))
+			} else {
+				successMessage(name)
+			}
+		})
+		infoMessage(fmt.Sprintf("%d image(s) traitée(s), %d échec(s), dans %s", done, failed, out))
+		readUserInput("Appuyez sur Entrée pour continuer")
+	}
+	time.Sleep(1 * time.Second)
+	return result, effectLabel(wm)
+}
+
+// runWatermarkCommand traite un dossier sans interface:
+// goimage watermark [options] <dossier>
+func runWatermarkCommand(args []string) int {
+	fs := flag.NewFlagSet("watermark", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: goimage watermark [options] <dossier>")
+		fs.PrintDefaults()
+	}
+	logo := fs.String("logo", "", "image du logo")
+	text := fs.String("text", "", "texte du filigrane (à défaut de logo)")
+	fontPath := fs.String("font", "", "police TrueType du texte (police intégrée par défaut)")
+	colorFlag := fs.String("color", "255,255,255", "couleur du texte R,G,B")
+	anchor := fs.String("anchor", "bottom-right", "ancre: top-left, top, top-right, left, center, right, bottom-left, bottom, bottom-right")
+	margin := fs.Int("margin", 20, "marge aux bords en pixels")
+	scale := fs.Float64("scale", 20, "largeur du motif en % de celle de l'image, de 0 à 100 (0 = taille d'origine)")
+	opacity := fs.Float64("opacity", 50, "opacité en %, de 0 à 100")
+	rotation := fs.Float64("rotate", 0, "rotation en degrés, sens antihoraire")
+	tiled := fs.Bool("tile", false, "mosaïque sur toute l'image")
+	spacing := fs.Int("spacing", 40, "espacement de la mosaïque en pixels")
+	out := fs.String("out", "", "dossier de sortie (défaut: <dossier>/filigrane)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || (*logo == "") == (*text == "") {
+		fmt.Fprintln(os.Stderr, "watermark: indiquez un dossier et soit -logo, soit -text")
+		fs.Usage()
+		return 2
+	}
+
+	a, ok := anchorFlags[*anchor]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "watermark: ancre inconnue: %s\n", *anchor)
+		return 2
+	}
+	// Les comparaisons écartent aussi NaN
+	if !(*opacity >= 0 && *opacity <= 100) {
+		fmt.Fprintf(os.Stderr, "watermark: opacité hors de 0 à 100: %g\n", *opacity)
+		return 2
+	}
+	if !(*scale >= 0 && *scale <= 100) {
+		fmt.Fprintf(os.Stderr, "watermark: largeur hors de 0 à 100: %g\n", *scale)
+		return 2
+	}
+	if *margin < 0 || *spacing < 0 {
+		fmt.Fprintln(os.Stderr, "watermark: la marge et l'espacement doivent être positifs")
+		return 2
+	}
+	wm := &effects.WatermarkEffect{
+		Anchor:   a,
+		Margin:   *margin,
+		Scale:    *scale / 100,
+		Opacity:  *opacity / 100,
+		Rotation: *rotation,
+		Tiled:    *tiled,
+		Spacing:  *spacing,
+	}
+	if *logo != "" {
+		mark, err := decodeImageFile(*logo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "watermark: %v\n", err)
+			return 1
+		}
+		wm.Mark = mark
+	} else {
+		var font *truetype.Font
+		if *fontPath != "" {
+			f, err := truetype.Open(*fontPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "watermark: police illisible: %v\n", err)
+				return 1
+			}
+			font = f
+		}
+		c, ok := parseRGB(*colorFlag)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "watermark: couleur invalide: %s\n", *colorFlag)
+			return 2
+		}
+		wm.Mark = effects.TextMark(*text, font, 96, 4, c)
+	}
+
+	dir := fs.Arg(0)
+	if *out == "" {
+		*out = filepath.Join(dir, "filigrane")
+	}
+	done, failed := watermarkFolder(dir, *out, wm, func(name string, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", IconError, name, err)
+		} else {
+			fmt.Printf("%s %s\n", IconSuccess, name)
+		}
+	})
+	fmt.Printf("%d image(s) filigranée(s) dans %s, %d échec(s)\n", done, *out, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// drawFilledCircleAA dessine un disque dont le bord est adouci selon la
+// part de chaque pixel couverte par le cercle
+func drawFilledCircleAA(img *image.RGBA, centerX, centerY, radius int, c color.Color) {
+	// Même étendue que la version crénelée: le bord passe à mi-pixel
+	edge := float64(radius) + 0.5
+	for y := centerY - radius - 1; y <= centerY+radius+1; y++ {
+		for x := centerX - radius - 1; x <= centerX+radius+1; x++ {
+			dx, dy := float64(x-centerX), float64(y-centerY)
+			d := math.Sqrt(dx*dx + dy*dy)
+			blendPixel(img, x, y, c, math.Min(1, math.Max(0, edge-d+0.5)))
+		}
+	}
+}
+
+// drawFilledTriangleAA dessine un triangle suréchantillonné; les sommets
+// désignent des centres de pixels, comme pour drawFilledTriangle
+func drawFilledTriangleAA(img *image.RGBA, x1, y1, x2, y2, x3, y3 int, c color.Color) {
+	poly := []point{
+		{float64(x1) + 0.5, float64(y1) + 0.5},
+		{float64(x2) + 0.5, float64(y2) + 0.5},
+		{float64(x3) + 0.5, float64(y3) + 0.5},
+	}
+	rasterizePolygons([][]point{poly}, img.Bounds(), NonZero, true, func(x, y int, coverage float64) {
+		blendPixel(img, x, y, c, coverage)
+	})
+}
+
+// drawLineAA trace une ligne de Xiaolin Wu: chaque colonne (ou ligne) se
+// partage entre les deux pixels les plus proches du tracé idéal
+func drawLineAA(img *image.RGBA, x1, y1, x2, y2 int, c color.Color) {
+	fx1, fy1, fx2, fy2 := float64(x1), float64(y1), float64(x2), float64(y2)
+	steep := math.Abs(fy2-fy1) > math.Abs(fx2-fx1)
+	if steep {
+		fx1, fy1, fx2, fy2 = fy1, fx1, fy2, fx2
+	}
+	if fx1 > fx2 {
+		fx1, fx2, fy1, fy2 = fx2, fx1, fy2, fy1
+	}
+
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		blendPixel(img, x, y, c, coverage)
+	}
+
+	gradient := 1.0
+	if dx := fx2 - fx1; dx != 0 {
+		gradient = (fy2 - fy1) / dx
+	}
+	y := fy1
+	for x := int(fx1); x <= int(fx2); x++ {
+		base := math.Floor(y)
+		frac := y - base
+		plot(x, int(base), 1-frac)
+		if frac > 0 {
+			plot(x, int(base)+1, frac)
+		}
+		y += gradient
+	}
+}
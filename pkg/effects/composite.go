@@ -0,0 +1,244 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Operator est un opérateur de composition de Porter-Duff
+type Operator int
+
+const (
+	SrcOver Operator = iota
+	DstOver
+	Src
+	Dst
+	SrcIn
+	DstIn
+	SrcOut
+	DstOut
+	SrcAtop
+	DstAtop
+	Xor
+	Clear
+)
+
+var operatorNames = []string{
+	"Source sur destination", "Destination sur source", "Source", "Destination",
+	"Source dans destination", "Destination dans source", "Source hors destination",
+	"Destination hors source", "Source au-dessus", "Destination au-dessus", "Ou exclusif", "Effacement",
+}
+
+func (o Operator) String() string {
+	if o < 0 || int(o) >= len(operatorNames) {
+		return "Inconnu"
+	}
+	return operatorNames[o]
+}
+
+// factors renvoie les parts Fa et Fb de la source et de la destination
+func (o Operator) factors(as, ab float64) (float64, float64) {
+	switch o {
+	case DstOver:
+		return 1 - ab, 1
+	case Src:
+		return 1, 0
+	case Dst:
+		return 0, 1
+	case SrcIn:
+		return ab, 0
+	case DstIn:
+		return 0, as
+	case SrcOut:
+		return 1 - ab, 0
+	case DstOut:
+		return 0, 1 - as
+	case SrcAtop:
+		return ab, 1 - as
+	case DstAtop:
+		return 1 - ab, as
+	case Xor:
+		return 1 - ab, 1 - as
+	case Clear:
+		return 0, 0
+	}
+	return 1, 1 - as
+}
+
+// BlendMode mélange les couleurs de la source et de la destination là où
+// les deux sont présentes
+type BlendMode int
+
+const (
+	Normal BlendMode = iota
+	Multiply
+	Screen
+	Overlay
+	SoftLight
+	Darken
+	Lighten
+	Difference
+)
+
+var blendModeNames = []string{
+	"Normal", "Produit", "Superposition claire", "Incrustation",
+	"Lumière douce", "Assombrir", "Éclaircir", "Différence",
+}
+
+func (m BlendMode) String() string {
+	if m < 0 || int(m) >= len(blendModeNames) {
+		return "Inconnu"
+	}
+	return blendModeNames[m]
+}
+
+// blend applique le mode à une composante (cb destination, cs source)
+func (m BlendMode) blend(cb, cs float64) float64 {
+	switch m {
+	case Multiply:
+		return cb * cs
+	case Screen:
+		return cb + cs - cb*cs
+	case Overlay:
+		// Lumière crue avec les rôles inversés
+		if cb <= 0.5 {
+			return cs * 2 * cb
+		}
+		return Screen.blend(cs, 2*cb-1)
+	case SoftLight:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		d := math.Sqrt(cb)
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case Darken:
+		return math.Min(cb, cs)
+	case Lighten:
+		return math.Max(cb, cs)
+	case Difference:
+		return math.Abs(cb - cs)
+	}
+	return cs
+}
+
+// Compositing règle la façon dont une source se pose sur l'image
+type Compositing struct {
+	// Opacity multiplie l'alpha de la source, de 0 à 1
+	Opacity  float64
+	Operator Operator
+	Mode     BlendMode
+}
+
+// compose pose la couleur src (non prémultipliée) sur le pixel (x, y);
+// coverage limite l'opération à la part du pixel couverte par la forme
+func (c *Compositing) compose(img *image.RGBA, x, y int, src color.NRGBA64, coverage float64) {
+	if !(image.Point{x, y}.In(img.Bounds())) || coverage <= 0 {
+		return
+	}
+	dst := img.RGBAAt(x, y)
+	// Destination en composantes non prémultipliées
+	ab := float64(dst.A) / 255
+	var cb [3]float64
+	if dst.A > 0 {
+		cb = [3]float64{float64(dst.R) / float64(dst.A), float64(dst.G) / float64(dst.A), float64(dst.B) / float64(dst.A)}
+	}
+	as := float64(src.A) / 0xffff * c.Opacity
+	cs := [3]float64{float64(src.R) / 0xffff, float64(src.G) / 0xffff, float64(src.B) / 0xffff}
+
+	fa, fb := c.Operator.factors(as, ab)
+	ao := as*fa + ab*fb
+	var out [3]float64
+	for i := range out {
+		mixed := (1-ab)*cs[i] + ab*c.Mode.blend(cb[i], cs[i])
+		// Résultat prémultiplié, interpolé selon la couverture
+		co := as*fa*mixed + ab*fb*cb[i]
+		out[i] = ab*cb[i] + (co-ab*cb[i])*coverage
+	}
+	ao = ab + (ao-ab)*coverage
+	img.SetRGBA(x, y, color.RGBA{
+		R: unit8(out[0]), G: unit8(out[1]), B: unit8(out[2]), A: unit8(ao),
+	})
+}
+
+// unit8 convertit une valeur de 0 à 1 en octet arrondi
+func unit8(v float64) uint8 {
+	return uint8(math.Round(math.Min(1, math.Max(0, v)) * 255))
+}
+
+// paintShape dessine une forme de couleur c sur result. Sans réglage de
+// composition, draw dessine directement; sinon la forme est d'abord tracée
+// en blanc pour obtenir sa couverture, puis composée pixel par pixel.
+// Une couleur qui est aussi une image (dégradé, image.Uniform) passe
+// toujours par la couverture pour être prise pixel par pixel
+func paintShape(result *image.RGBA, c color.Color, comp *Compositing, draw func(dst *image.RGBA, c color.Color)) {
+	paint, varying := c.(image.Image)
+	if comp == nil {
+		if !varying {
+			draw(result, c)
+			return
+		}
+		comp = &Compositing{Opacity: 1}
+	}
+	bounds := result.Bounds()
+	mask := image.NewRGBA(bounds)
+	draw(mask, color.White)
+	src := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if a := mask.RGBAAt(x, y).A; a > 0 {
+				if varying {
+					src = color.NRGBA64Model.Convert(paint.At(x, y)).(color.NRGBA64)
+				}
+				comp.compose(result, x, y, src, float64(a)/255)
+			}
+		}
+	}
+}
+
+// CompositeEffect pose une image sur une autre à la position (X, Y)
+type CompositeEffect struct {
+	Source      image.Image
+	X, Y        int
+	Compositing Compositing
+	// Clip prend l'alpha de la source pour sa couverture, comme une forme:
+	// l'opérateur épargne alors les pixels transparents de la source
+	Clip bool
+}
+
+func (e *CompositeEffect) Name() string { return "Superposition d'image" }
+func (e *CompositeEffect) Description() string {
+	return "Compose une image sur l'image courante (opacité, Porter-Duff, modes de fusion)"
+}
+
+// Apply limite l'opération au rectangle occupé par la source
+func (e *CompositeEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	if e.Source == nil {
+		return result
+	}
+	sb := e.Source.Bounds()
+	for y := sb.Min.Y; y < sb.Max.Y; y++ {
+		for x := sb.Min.X; x < sb.Max.X; x++ {
+			src := color.NRGBA64Model.Convert(e.Source.At(x, y)).(color.NRGBA64)
+			coverage := 1.0
+			if e.Clip {
+				coverage = float64(src.A) / 0xffff
+				src.A = 0xffff
+			}
+			dx := bounds.Min.X + e.X + x - sb.Min.X
+			dy := bounds.Min.Y + e.Y + y - sb.Min.Y
+			e.Compositing.compose(result, dx, dy, src, coverage)
+		}
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// ColorDistance choisit l'espace dans lequel deux couleurs sont comparées
+type ColorDistance int
+
+const (
+	// RGBDistance mesure l'écart euclidien des composantes R, G, B et alpha
+	RGBDistance ColorDistance = iota
+	// LabDistance mesure l'écart perceptuel ΔE (CIE76) dans l'espace Lab
+	LabDistance
+)
+
+// Connectivity indique quels voisins prolongent une zone
+type Connectivity int
+
+const (
+	// FourWay relie les pixels par leurs côtés
+	FourWay Connectivity = iota
+	// EightWay relie aussi les pixels par leurs coins
+	EightWay
+)
+
+// FloodFillEffect remplit la zone de couleur proche de celle du pixel de
+// départ (pot de peinture)
+type FloodFillEffect struct {
+	X, Y int
+	// Fill est la couleur de remplissage, unie ou dégradé
+	Fill color.Color
+	// Tolerance va de 0 (couleur identique) à 100. En RGB, c'est un
+	// pourcentage de l'écart maximal; en Lab, l'écart ΔE, 100 séparant le
+	// noir du blanc
+	Tolerance    float64
+	Distance     ColorDistance
+	Connectivity Connectivity
+	// Global remplace toutes les couleurs proches de l'image, reliées ou non
+	Global bool
+	// Sample fournit les couleurs comparées, aux mêmes coordonnées que
+	// l'image remplie (par exemple tous les calques composés); nil pour
+	// comparer les couleurs de l'image remplie elle-même
+	Sample      image.Image
+	Compositing *Compositing
+}
+
+func (f *FloodFillEffect) Name() string { return "Pot de peinture" }
+func (f *FloodFillEffect) Description() string {
+	return "Remplit la zone de couleur proche du point de départ"
+}
+
+func (f *FloodFillEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	if f.Fill == nil || !(image.Point{f.X, f.Y}.In(bounds)) {
+		return result
+	}
+
+	sample := f.Sample
+	if sample == nil {
+		sample = result
+	}
+	region := f.region(sample, bounds)
+	w := bounds.Dx()
+	paintShape(result, f.Fill, f.Compositing, func(dst *image.RGBA, c color.Color) {
+		for i, in := range region {
+			if in {
+				dst.Set(bounds.Min.X+i%w, bounds.Min.Y+i/w, c)
+			}
+		}
+	})
+	return result
+}
+
+// region renvoie la zone remplie, une case par pixel de bounds ligne par
+// ligne, d'après les couleurs de img; nil si le point de départ est hors de
+// bounds
+func (f *FloodFillEffect) region(img image.Image, bounds image.Rectangle) []bool {
+	if !(image.Point{f.X, f.Y}.In(bounds)) {
+		return nil
+	}
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Les pixels assez proches de la couleur de départ sont calculés une
+	// seule fois; la recherche de zone ne fait ensuite que les relier
+	match := make([]bool, w*h)
+	seed := f.measure(img.At(f.X, f.Y))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			match[y*w+x] = f.distance(seed, f.measure(img.At(bounds.Min.X+x, bounds.Min.Y+y))) <= f.Tolerance
+		}
+	}
+	if f.Global {
+		return match
+	}
+	return scanlineFill(match, w, h, f.X-bounds.Min.X, f.Y-bounds.Min.Y, f.Connectivity == EightWay)
+}
+
+// scanlineFill relie les pixels acceptés depuis (sx, sy) par segments
+// horizontaux: chaque segment est rempli d'un bloc, puis les lignes voisines
+// ne reçoivent qu'un germe par segment candidat. La pile explicite évite la
+// récursion sur les grandes zones
+func scanlineFill(match []bool, w, h, sx, sy int, diagonal bool) []bool {
+	filled := make([]bool, w*h)
+	stack := []image.Point{{sx, sy}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		row := p.Y * w
+		if filled[row+p.X] || !match[row+p.X] {
+			continue
+		}
+		left, right := p.X, p.X
+		for left > 0 && match[row+left-1] && !filled[row+left-1] {
+			left--
+		}
+		for right < w-1 && match[row+right+1] && !filled[row+right+1] {
+			right++
+		}
+		for x := left; x <= right; x++ {
+			filled[row+x] = true
+		}
+
+		// En 8-connexité, les diagonales des extrémités comptent aussi
+		lo, hi := left, right
+		if diagonal {
+			lo, hi = maxInt(left-1, 0), minInt(right+1, w-1)
+		}
+		for _, ny := range [2]int{p.Y - 1, p.Y + 1} {
+			if ny < 0 || ny >= h {
+				continue
+			}
+			nrow := ny * w
+			inRun := false
+			for x := lo; x <= hi; x++ {
+				ok := match[nrow+x] && !filled[nrow+x]
+				if ok && !inRun {
+					stack = append(stack, image.Point{x, ny})
+				}
+				inRun = ok
+			}
+		}
+	}
+	return filled
+}
+
+// measure renvoie les coordonnées de la couleur dans l'espace de comparaison
+// (R, G, B, A de 0 à 255, ou L, a, b et A)
+func (f *FloodFillEffect) measure(c color.Color) [4]float64 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if f.Distance == LabDistance {
+		l, a, b := toLab(n.R, n.G, n.B)
+		return [4]float64{l, a, b, float64(n.A)}
+	}
+	return [4]float64{float64(n.R), float64(n.G), float64(n.B), float64(n.A)}
+}
+
+// distance ramène l'écart entre deux mesures à l'échelle de la tolérance
+func (f *FloodFillEffect) distance(p, q [4]float64) float64 {
+	if f.Distance == LabDistance {
+		// L'alpha pèse comme la clarté: 100 d'un opaque à un transparent
+		da := (p[3] - q[3]) / 255 * 100
+		return math.Sqrt(sq(p[0]-q[0]) + sq(p[1]-q[1]) + sq(p[2]-q[2]) + sq(da))
+	}
+	return math.Sqrt(sq(p[0]-q[0])+sq(p[1]-q[1])+sq(p[2]-q[2])+sq(p[3]-q[3])) / (2 * 255) * 100
+}
+
+func sq(v float64) float64 { return v * v }
+
+// srgbLinear associe à chaque octet sRGB sa valeur linéaire
+var srgbLinear = func() (t [256]float64) {
+	for i := range t {
+		v := float64(i) / 255
+		if v <= 0.04045 {
+			t[i] = v / 12.92
+		} else {
+			t[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return t
+}()
+
+// toLab convertit une couleur sRGB en CIE Lab (blanc de référence D65)
+func toLab(r, g, b uint8) (float64, float64, float64) {
+	lr, lg, lb := srgbLinear[r], srgbLinear[g], srgbLinear[b]
+	x := (0.4124*lr + 0.3576*lg + 0.1805*lb) / 0.95047
+	y := 0.2126*lr + 0.7152*lg + 0.0722*lb
+	z := (0.0193*lr + 0.1192*lg + 0.9505*lb) / 1.08883
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+func labF(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d*d*d {
+		return math.Cbrt(t)
+	}
+	return t/(3*d*d) + 4.0/29
+}
@@ -0,0 +1,243 @@
+package effects
+
+// Police bitmap 8 × 16 générée depuis Inconsolata (Raph Levien et Cyreal,
+// licence BSD, rendu de golang.org/x/image/font/inconsolata) avec un seuil de
+// couverture de 100/255. Chaque glyphe compte 16 lignes de 8 pixels, le bit
+// de poids fort à gauche; la ligne de base passe sous la 13e ligne
+
+// latin1Glyphs couvre U+0020 à U+00FF; les codes de contrôle U+007F à U+009F
+// sont vides
+var latin1Glyphs = [224][16]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // espace
+	{0x00, 0x00, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x00, 0x00, 0x10, 0x10, 0x00, 0x00, 0x00}, // !
+	{0x00, 0x00, 0x14, 0x14, 0x14, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // "
+	{0x00, 0x00, 0x00, 0x12, 0x12, 0x12, 0x7f, 0x12, 0x36, 0x7f, 0x24, 0x24, 0x24, 0x00, 0x00, 0x00}, // #
+	{0x00, 0x00, 0x00, 0x10, 0x3c, 0x56, 0x50, 0x30, 0x1c, 0x16, 0x12, 0x56, 0x3c, 0x10, 0x00, 0x00}, // $
+	{0x00, 0x00, 0x00, 0x62, 0x94, 0x94, 0x68, 0x08, 0x10, 0x16, 0x29, 0x29, 0x46, 0x00, 0x00, 0x00}, // %
+	{0x00, 0x00, 0x00, 0x18, 0x24, 0x24, 0x3c, 0x18, 0x39, 0x47, 0x42, 0x66, 0x39, 0x00, 0x00, 0x00}, // &
+	{0x00, 0x00, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // '
+	{0x00, 0x00, 0x02, 0x0c, 0x18, 0x10, 0x20, 0x20, 0x20, 0x20, 0x20, 0x30, 0x10, 0x08, 0x04, 0x02}, // (
+	{0x00, 0x00, 0x40, 0x30, 0x10, 0x08, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x08, 0x10, 0x30, 0x40}, // )
+	{0x00, 0x00, 0x00, 0x00, 0x08, 0x08, 0x6b, 0x1c, 0x1c, 0x36, 0x22, 0x00, 0x00, 0x00, 0x00, 0x00}, // *
+	{0x00, 0x00, 0x00, 0x00, 0x08, 0x08, 0x08, 0x7f, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00}, // +
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0x18, 0x08, 0x10, 0x00}, // ,
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7e, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // -
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x10, 0x00, 0x00, 0x00}, // .
+	{0x00, 0x00, 0x02, 0x06, 0x04, 0x04, 0x08, 0x08, 0x10, 0x10, 0x20, 0x20, 0x40, 0x40, 0x00, 0x00}, // /
+	{0x00, 0x00, 0x00, 0x18, 0x24, 0x66, 0x46, 0x4a, 0x52, 0x62, 0x62, 0x24, 0x18, 0x00, 0x00, 0x00}, // 0
+	{0x00, 0x00, 0x00, 0x18, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00}, // 1
+	{0x00, 0x00, 0x00, 0x38, 0x46, 0x02, 0x02, 0x06, 0x0c, 0x18, 0x20, 0x60, 0x7e, 0x00, 0x00, 0x00}, // 2
+	{0x00, 0x00, 0x00, 0x38, 0x4c, 0x04, 0x0c, 0x18, 0x0c, 0x04, 0x04, 0x4c, 0x38, 0x00, 0x00, 0x00}, // 3
+	{0x00, 0x00, 0x00, 0x04, 0x0c, 0x14, 0x14, 0x24, 0x64, 0x7e, 0x04, 0x04, 0x04, 0x00, 0x00, 0x00}, // 4
+	{0x00, 0x00, 0x00, 0x3e, 0x20, 0x60, 0x78, 0x64, 0x02, 0x02, 0x02, 0x66, 0x38, 0x00, 0x00, 0x00}, // 5
+	{0x00, 0x00, 0x00, 0x1c, 0x22, 0x60, 0x40, 0x78, 0x64, 0x42, 0x42, 0x26, 0x3c, 0x00, 0x00, 0x00}, // 6
+	{0x00, 0x00, 0x00, 0x7e, 0x06, 0x04, 0x0c, 0x08, 0x08, 0x18, 0x10, 0x10, 0x30, 0x00, 0x00, 0x00}, // 7
+	{0x00, 0x00, 0x00, 0x3c, 0x66, 0x42, 0x64, 0x3c, 0x24, 0x42, 0x42, 0x66, 0x3c, 0x00, 0x00, 0x00}, // 8
+	{0x00, 0x00, 0x00, 0x18, 0x64, 0x42, 0x42, 0x26, 0x1e, 0x02, 0x06, 0x44, 0x38, 0x00, 0x00, 0x00}, // 9
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x08, 0x00, 0x00, 0x00, 0x08, 0x08, 0x00, 0x00, 0x00}, // :
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x08, 0x00, 0x00, 0x00, 0x18, 0x18, 0x08, 0x10, 0x00}, // ;
+	{0x00, 0x00, 0x00, 0x00, 0x01, 0x06, 0x18, 0x60, 0x60, 0x18, 0x06, 0x01, 0x00, 0x00, 0x00, 0x00}, // <
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7f, 0x00, 0x00, 0x7f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // =
+	{0x00, 0x00, 0x00, 0x00, 0x80, 0x60, 0x18, 0x06, 0x06, 0x18, 0x60, 0x80, 0x00, 0x00, 0x00, 0x00}, // >
+	{0x00, 0x00, 0x3c, 0x66, 0x02, 0x02, 0x04, 0x08, 0x08, 0x08, 0x00, 0x08, 0x08, 0x00, 0x00, 0x00}, // ?
+	{0x00, 0x00, 0x00, 0x1c, 0x32, 0x41, 0x4f, 0x59, 0x53, 0x4f, 0x20, 0x10, 0x0f, 0x00, 0x00, 0x00}, // @
+	{0x00, 0x00, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // A
+	{0x00, 0x00, 0x00, 0x7c, 0x46, 0x42, 0x46, 0x7c, 0x46, 0x42, 0x42, 0x46, 0x7c, 0x00, 0x00, 0x00}, // B
+	{0x00, 0x00, 0x00, 0x1c, 0x26, 0x60, 0x40, 0x40, 0x40, 0x40, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // C
+	{0x00, 0x00, 0x00, 0x78, 0x44, 0x46, 0x42, 0x42, 0x42, 0x42, 0x46, 0x4c, 0x78, 0x00, 0x00, 0x00}, // D
+	{0x00, 0x00, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // E
+	{0x00, 0x00, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x40, 0x00, 0x00, 0x00}, // F
+	{0x00, 0x00, 0x00, 0x3c, 0x66, 0xc0, 0x80, 0x80, 0x8e, 0x82, 0xc2, 0x62, 0x3c, 0x00, 0x00, 0x00}, // G
+	{0x00, 0x00, 0x00, 0x42, 0x42, 0x42, 0x42, 0x7e, 0x42, 0x42, 0x42, 0x42, 0x42, 0x00, 0x00, 0x00}, // H
+	{0x00, 0x00, 0x00, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x7c, 0x00, 0x00, 0x00}, // I
+	{0x00, 0x00, 0x00, 0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x4c, 0x38, 0x00, 0x00, 0x00}, // J
+	{0x00, 0x00, 0x00, 0x42, 0x44, 0x48, 0x50, 0x70, 0x50, 0x48, 0x4c, 0x44, 0x42, 0x00, 0x00, 0x00}, // K
+	{0x00, 0x00, 0x00, 0x40, 0x40, 0x40, 0x40, 0x40, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // L
+	{0x00, 0x00, 0x00, 0x82, 0xc6, 0xc6, 0xaa, 0xba, 0x92, 0x82, 0x82, 0x82, 0x82, 0x00, 0x00, 0x00}, // M
+	{0x00, 0x00, 0x00, 0x42, 0x62, 0x62, 0x52, 0x5a, 0x4a, 0x4e, 0x46, 0x46, 0x42, 0x00, 0x00, 0x00}, // N
+	{0x00, 0x00, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // O
+	{0x00, 0x00, 0x00, 0x7c, 0x46, 0x42, 0x42, 0x46, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x00, 0x00, 0x00}, // P
+	{0x00, 0x00, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x10, 0x0c, 0x00}, // Q
+	{0x00, 0x00, 0x00, 0x7c, 0x46, 0x42, 0x42, 0x46, 0x7c, 0x48, 0x44, 0x46, 0x42, 0x00, 0x00, 0x00}, // R
+	{0x00, 0x00, 0x00, 0x3c, 0x62, 0x40, 0x60, 0x38, 0x0c, 0x02, 0x02, 0x46, 0x3c, 0x00, 0x00, 0x00}, // S
+	{0x00, 0x00, 0x00, 0xfe, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x00, 0x00, 0x00}, // T
+	{0x00, 0x00, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x24, 0x38, 0x00, 0x00, 0x00}, // U
+	{0x00, 0x00, 0x00, 0x82, 0xc2, 0x44, 0x44, 0x64, 0x28, 0x28, 0x38, 0x10, 0x10, 0x00, 0x00, 0x00}, // V
+	{0x00, 0x00, 0x00, 0x41, 0x49, 0x49, 0x4d, 0x5d, 0x76, 0x36, 0x36, 0x22, 0x22, 0x00, 0x00, 0x00}, // W
+	{0x00, 0x00, 0x00, 0xc6, 0x44, 0x6c, 0x38, 0x10, 0x38, 0x28, 0x6c, 0x44, 0xc2, 0x00, 0x00, 0x00}, // X
+	{0x00, 0x00, 0x00, 0x43, 0x22, 0x22, 0x14, 0x1c, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00}, // Y
+	{0x00, 0x00, 0x00, 0x7e, 0x02, 0x04, 0x0c, 0x08, 0x10, 0x10, 0x20, 0x60, 0x7e, 0x00, 0x00, 0x00}, // Z
+	{0x00, 0x00, 0x3e, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3e, 0x00, 0x00}, // [
+	{0x00, 0x00, 0x40, 0x40, 0x20, 0x20, 0x10, 0x10, 0x08, 0x08, 0x04, 0x04, 0x06, 0x02, 0x00, 0x00}, // \
+	{0x00, 0x00, 0x7c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x7c, 0x00, 0x00}, // ]
+	{0x00, 0x00, 0x00, 0x08, 0x1c, 0x14, 0x22, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ^
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7e, 0x00, 0x00}, // _
+	{0x00, 0x00, 0x00, 0x20, 0x10, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // `
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // a
+	{0x00, 0x00, 0x40, 0x40, 0x40, 0x40, 0x58, 0x64, 0x42, 0x42, 0x42, 0x64, 0x78, 0x00, 0x00, 0x00}, // b
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x62, 0x40, 0x40, 0x40, 0x62, 0x1c, 0x00, 0x00, 0x00}, // c
+	{0x00, 0x00, 0x02, 0x02, 0x02, 0x02, 0x1a, 0x26, 0x42, 0x42, 0x42, 0x26, 0x1a, 0x00, 0x00, 0x00}, // d
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0x24, 0x42, 0x7e, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // e
+	{0x00, 0x00, 0x0f, 0x19, 0x10, 0x10, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x00, 0x00, 0x00}, // f
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3e, 0x6c, 0x44, 0x6c, 0x38, 0x40, 0x7c, 0x42, 0x46, 0x3c}, // g
+	{0x00, 0x00, 0x40, 0x40, 0x40, 0x40, 0x5c, 0x66, 0x42, 0x42, 0x42, 0x42, 0x42, 0x00, 0x00, 0x00}, // h
+	{0x00, 0x00, 0x00, 0x08, 0x08, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // i
+	{0x00, 0x00, 0x00, 0x04, 0x04, 0x00, 0x3c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x4c, 0x78}, // j
+	{0x00, 0x00, 0x40, 0x40, 0x40, 0x40, 0x44, 0x48, 0x50, 0x78, 0x48, 0x44, 0x42, 0x00, 0x00, 0x00}, // k
+	{0x00, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // l
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xee, 0x9a, 0x92, 0x92, 0x92, 0x92, 0x92, 0x00, 0x00, 0x00}, // m
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x5c, 0x66, 0x42, 0x42, 0x42, 0x42, 0x42, 0x00, 0x00, 0x00}, // n
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // o
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x5c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x78, 0x40, 0x40, 0x40}, // p
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3e, 0x66, 0x42, 0x42, 0x42, 0x26, 0x1a, 0x02, 0x02, 0x02}, // q
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2e, 0x32, 0x20, 0x20, 0x20, 0x20, 0x20, 0x00, 0x00, 0x00}, // r
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x42, 0x70, 0x1c, 0x02, 0x46, 0x3c, 0x00, 0x00, 0x00}, // s
+	{0x00, 0x00, 0x00, 0x00, 0x10, 0x10, 0x7e, 0x10, 0x10, 0x10, 0x10, 0x12, 0x0e, 0x00, 0x00, 0x00}, // t
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x66, 0x3a, 0x00, 0x00, 0x00}, // u
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x41, 0x63, 0x22, 0x36, 0x14, 0x1c, 0x08, 0x00, 0x00, 0x00}, // v
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x82, 0x92, 0x9a, 0x7a, 0x6c, 0x6c, 0x44, 0x00, 0x00, 0x00}, // w
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x66, 0x24, 0x18, 0x18, 0x18, 0x24, 0x66, 0x00, 0x00, 0x00}, // x
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x42, 0x22, 0x26, 0x34, 0x14, 0x1c, 0x08, 0x08, 0x10, 0x60}, // y
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7e, 0x04, 0x08, 0x18, 0x30, 0x20, 0x7e, 0x00, 0x00, 0x00}, // z
+	{0x00, 0x00, 0x00, 0x0e, 0x18, 0x10, 0x10, 0x10, 0x30, 0x60, 0x10, 0x10, 0x10, 0x10, 0x18, 0x0e}, // {
+	{0x00, 0x00, 0x00, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00}, // |
+	{0x00, 0x00, 0x00, 0x70, 0x18, 0x08, 0x08, 0x08, 0x08, 0x06, 0x08, 0x08, 0x08, 0x08, 0x18, 0x70}, // }
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x39, 0x46, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ~
+	{}, // U+007F
+	{}, // U+0080
+	{}, // U+0081
+	{}, // U+0082
+	{}, // U+0083
+	{}, // U+0084
+	{}, // U+0085
+	{}, // U+0086
+	{}, // U+0087
+	{}, // U+0088
+	{}, // U+0089
+	{}, // U+008A
+	{}, // U+008B
+	{}, // U+008C
+	{}, // U+008D
+	{0x24, 0x18, 0x00, 0x7e, 0x02, 0x04, 0x0c, 0x08, 0x10, 0x10, 0x20, 0x60, 0x7e, 0x00, 0x00, 0x00}, // 
+	{}, // U+008F
+	{}, // U+0090
+	{}, // U+0091
+	{}, // U+0092
+	{}, // U+0093
+	{}, // U+0094
+	{}, // U+0095
+	{}, // U+0096
+	{}, // U+0097
+	{}, // U+0098
+	{}, // U+0099
+	{}, // U+009A
+	{}, // U+009B
+	{}, // U+009C
+	{}, // U+009D
+	{0x00, 0x00, 0x14, 0x1c, 0x08, 0x00, 0x7e, 0x04, 0x08, 0x18, 0x30, 0x20, 0x7e, 0x00, 0x00, 0x00}, // 
+	{}, // U+009F
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // espace insécable
+	{0x00, 0x00, 0x08, 0x08, 0x00, 0x00, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00}, // ¡
+	{0x00, 0x00, 0x04, 0x0c, 0x1c, 0x2e, 0x48, 0x48, 0x48, 0x48, 0x3a, 0x1c, 0x10, 0x10, 0x00, 0x00}, // ¢
+	{0x00, 0x00, 0x00, 0x1e, 0x32, 0x20, 0x20, 0x38, 0x10, 0x10, 0x10, 0x79, 0x47, 0x00, 0x00, 0x00}, // £
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x3e, 0x36, 0x22, 0x36, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ¤
+	{0x00, 0x00, 0x00, 0x43, 0x22, 0x26, 0x14, 0x1c, 0x08, 0x3e, 0x08, 0x3e, 0x08, 0x00, 0x00, 0x00}, // ¥
+	{0x00, 0x00, 0x00, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x00}, // ¦
+	{0x00, 0x00, 0x38, 0x44, 0x40, 0x60, 0x38, 0x4c, 0x64, 0x38, 0x08, 0x04, 0x4c, 0x38, 0x00, 0x00}, // §
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ¨
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x66, 0xdf, 0xa1, 0xa1, 0xdf, 0x66, 0x3c, 0x00, 0x00, 0x00}, // ©
+	{0x00, 0x00, 0x00, 0x38, 0x24, 0x1c, 0x24, 0x2c, 0x3c, 0x00, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x00}, // ª
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0x24, 0x48, 0x24, 0x12, 0x00, 0x00, 0x00, 0x00}, // «
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7c, 0x04, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ¬
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7e, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // trait d'union conditionnel
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x66, 0xf3, 0xa9, 0xb9, 0xeb, 0x66, 0x3c, 0x00, 0x00, 0x00}, // ®
+	{0x00, 0x00, 0x00, 0x3c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ¯
+	{0x00, 0x00, 0x00, 0x18, 0x24, 0x24, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // °
+	{0x00, 0x00, 0x00, 0x00, 0x08, 0x08, 0x08, 0x7f, 0x08, 0x08, 0x08, 0x00, 0x7f, 0x00, 0x00, 0x00}, // ±
+	{0x00, 0x00, 0x00, 0x38, 0x44, 0x04, 0x18, 0x20, 0x7c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ²
+	{0x00, 0x00, 0x00, 0x1c, 0x22, 0x0e, 0x02, 0x22, 0x1c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ³
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ´
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x44, 0x44, 0x44, 0x44, 0x44, 0x6d, 0x7f, 0x40, 0x40, 0x40}, // µ
+	{0x00, 0x00, 0x3e, 0x7a, 0x7a, 0x7a, 0x3a, 0x0a, 0x0a, 0x0a, 0x0a, 0x0a, 0x0a, 0x0a, 0x00, 0x00}, // ¶
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ·
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x3c}, // ¸
+	{0x00, 0x00, 0x00, 0x18, 0x38, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // ¹
+	{0x00, 0x00, 0x00, 0x38, 0x6c, 0x44, 0x44, 0x6c, 0x38, 0x00, 0x7c, 0x00, 0x00, 0x00, 0x00, 0x00}, // º
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x48, 0x24, 0x12, 0x24, 0x48, 0x00, 0x00, 0x00, 0x00}, // »
+	{0x00, 0x00, 0xc2, 0x46, 0x44, 0x44, 0x48, 0x08, 0x10, 0x12, 0x26, 0x2a, 0x6f, 0x42, 0x00, 0x00}, // ¼
+	{0x00, 0x00, 0xc2, 0x46, 0x44, 0x44, 0x48, 0x08, 0x10, 0x1e, 0x21, 0x22, 0x64, 0x4f, 0x00, 0x00}, // ½
+	{0x00, 0x00, 0xf2, 0x16, 0x34, 0x94, 0xf8, 0x08, 0x10, 0x12, 0x26, 0x2a, 0x6f, 0x42, 0x00, 0x00}, // ¾
+	{0x00, 0x00, 0x10, 0x10, 0x00, 0x10, 0x10, 0x10, 0x20, 0x40, 0x40, 0x66, 0x3c, 0x00, 0x00, 0x00}, // ¿
+	{0x30, 0x18, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // À
+	{0x18, 0x30, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // Á
+	{0x18, 0x24, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // Â
+	{0x34, 0x58, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // Ã
+	{0x24, 0x24, 0x00, 0x00, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // Ä
+	{0x18, 0x28, 0x10, 0x10, 0x10, 0x38, 0x28, 0x28, 0x44, 0x7c, 0x46, 0xc2, 0x82, 0x00, 0x00, 0x00}, // Å
+	{0x00, 0x00, 0x00, 0x1f, 0x18, 0x28, 0x28, 0x2f, 0x48, 0x78, 0x48, 0xc8, 0x8f, 0x00, 0x00, 0x00}, // Æ
+	{0x00, 0x00, 0x00, 0x1c, 0x26, 0x60, 0x40, 0x40, 0x40, 0x40, 0x60, 0x22, 0x1c, 0x08, 0x04, 0x3c}, // Ç
+	{0x30, 0x18, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // È
+	{0x0c, 0x18, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // É
+	{0x18, 0x24, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // Ê
+	{0x24, 0x24, 0x00, 0x7e, 0x40, 0x40, 0x40, 0x7c, 0x40, 0x40, 0x40, 0x40, 0x7e, 0x00, 0x00, 0x00}, // Ë
+	{0x30, 0x18, 0x00, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x7c, 0x00, 0x00, 0x00}, // Ì
+	{0x0c, 0x18, 0x00, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x7c, 0x00, 0x00, 0x00}, // Í
+	{0x18, 0x24, 0x00, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x7c, 0x00, 0x00, 0x00}, // Î
+	{0x24, 0x24, 0x00, 0x7c, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x7c, 0x00, 0x00, 0x00}, // Ï
+	{0x00, 0x00, 0x00, 0x78, 0x44, 0x46, 0x42, 0xf2, 0x42, 0x42, 0x46, 0x4c, 0x78, 0x00, 0x00, 0x00}, // Ð
+	{0x1a, 0x2c, 0x00, 0x42, 0x62, 0x62, 0x52, 0x5a, 0x4a, 0x4e, 0x46, 0x46, 0x42, 0x00, 0x00, 0x00}, // Ñ
+	{0x30, 0x18, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // Ò
+	{0x18, 0x30, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // Ó
+	{0x18, 0x24, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // Ô
+	{0x34, 0x58, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // Õ
+	{0x24, 0x24, 0x00, 0x38, 0x44, 0xc6, 0x82, 0x82, 0x82, 0x82, 0xc6, 0x44, 0x38, 0x00, 0x00, 0x00}, // Ö
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x42, 0x24, 0x18, 0x18, 0x24, 0x42, 0x00, 0x00, 0x00, 0x00, 0x00}, // ×
+	{0x00, 0x00, 0x02, 0x3e, 0x44, 0xce, 0x8a, 0x92, 0x92, 0xa2, 0xe6, 0x64, 0xf8, 0x80, 0x00, 0x00}, // Ø
+	{0x18, 0x0c, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x24, 0x38, 0x00, 0x00, 0x00}, // Ù
+	{0x0c, 0x18, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x24, 0x38, 0x00, 0x00, 0x00}, // Ú
+	{0x18, 0x24, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x24, 0x38, 0x00, 0x00, 0x00}, // Û
+	{0x24, 0x24, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x42, 0x24, 0x38, 0x00, 0x00, 0x00}, // Ü
+	{0x0c, 0x18, 0x00, 0x43, 0x22, 0x22, 0x14, 0x1c, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00}, // Ý
+	{0x00, 0x00, 0x00, 0x40, 0x40, 0x7c, 0x46, 0x42, 0x42, 0x46, 0x7c, 0x40, 0x40, 0x00, 0x00, 0x00}, // Þ
+	{0x00, 0x00, 0x38, 0x6c, 0x44, 0x44, 0x4c, 0x58, 0x44, 0x42, 0x42, 0x46, 0x5c, 0x00, 0x00, 0x00}, // ß
+	{0x00, 0x00, 0x18, 0x08, 0x0c, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // à
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // á
+	{0x00, 0x00, 0x08, 0x1c, 0x14, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // â
+	{0x00, 0x00, 0x00, 0x1a, 0x24, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // ã
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // ä
+	{0x00, 0x00, 0x1c, 0x14, 0x1c, 0x00, 0x1c, 0x26, 0x02, 0x3e, 0x62, 0x46, 0x3e, 0x00, 0x00, 0x00}, // å
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x76, 0x99, 0x09, 0x7f, 0xc8, 0x8c, 0x77, 0x00, 0x00, 0x00}, // æ
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3c, 0x62, 0x40, 0x40, 0x40, 0x62, 0x3c, 0x08, 0x04, 0x3c}, // ç
+	{0x00, 0x00, 0x30, 0x10, 0x18, 0x00, 0x18, 0x24, 0x42, 0x7e, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // è
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x18, 0x24, 0x42, 0x7e, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // é
+	{0x00, 0x00, 0x08, 0x1c, 0x14, 0x00, 0x18, 0x24, 0x42, 0x7e, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // ê
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x18, 0x24, 0x42, 0x7e, 0x40, 0x22, 0x1c, 0x00, 0x00, 0x00}, // ë
+	{0x00, 0x00, 0x30, 0x10, 0x18, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // ì
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // í
+	{0x00, 0x00, 0x08, 0x1c, 0x14, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // î
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x38, 0x08, 0x08, 0x08, 0x08, 0x08, 0x3e, 0x00, 0x00, 0x00}, // ï
+	{0x00, 0x00, 0x30, 0x1e, 0x3c, 0x04, 0x1e, 0x26, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // ð
+	{0x00, 0x00, 0x00, 0x1a, 0x24, 0x00, 0x5c, 0x66, 0x42, 0x42, 0x42, 0x42, 0x42, 0x00, 0x00, 0x00}, // ñ
+	{0x00, 0x00, 0x30, 0x10, 0x18, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // ò
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // ó
+	{0x00, 0x00, 0x08, 0x1c, 0x14, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // ô
+	{0x00, 0x00, 0x00, 0x1a, 0x24, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // õ
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x3c, 0x64, 0x42, 0x42, 0x42, 0x64, 0x18, 0x00, 0x00, 0x00}, // ö
+	{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x7c, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00}, // ÷
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x3c, 0x6c, 0x4a, 0x5a, 0x52, 0x64, 0x38, 0x40, 0x00, 0x00}, // ø
+	{0x00, 0x00, 0x30, 0x10, 0x18, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x66, 0x3a, 0x00, 0x00, 0x00}, // ù
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x66, 0x3a, 0x00, 0x00, 0x00}, // ú
+	{0x00, 0x00, 0x08, 0x1c, 0x14, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x66, 0x3a, 0x00, 0x00, 0x00}, // û
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x42, 0x42, 0x42, 0x42, 0x42, 0x66, 0x3a, 0x00, 0x00, 0x00}, // ü
+	{0x00, 0x00, 0x0c, 0x08, 0x18, 0x00, 0x42, 0x22, 0x26, 0x34, 0x14, 0x1c, 0x08, 0x08, 0x10, 0x60}, // ý
+	{0x00, 0x00, 0x40, 0x40, 0x40, 0x40, 0x5c, 0x66, 0x42, 0x42, 0x42, 0x64, 0x78, 0x40, 0x40, 0x40}, // þ
+	{0x00, 0x00, 0x00, 0x24, 0x24, 0x00, 0x42, 0x22, 0x26, 0x34, 0x14, 0x1c, 0x08, 0x08, 0x10, 0x60}, // ÿ
+}
+
+// extraGlyphs complète le Latin-1 pour le français et l'euro
+var extraGlyphs = map[rune][16]byte{
+	'Œ': {0x00, 0x00, 0x00, 0x7f, 0x58, 0x88, 0x88, 0x8f, 0x88, 0x88, 0x88, 0x58, 0x3f, 0x00, 0x00, 0x00},
+	'œ': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7e, 0x49, 0x89, 0x8f, 0x88, 0x48, 0x7f, 0x00, 0x00, 0x00},
+	'Ÿ': {0x24, 0x24, 0x00, 0x43, 0x22, 0x22, 0x14, 0x1c, 0x08, 0x08, 0x08, 0x08, 0x08, 0x00, 0x00, 0x00},
+	'€': {0x00, 0x00, 0x00, 0x1c, 0x22, 0x40, 0xfc, 0x40, 0xf8, 0x40, 0x60, 0x32, 0x1c, 0x00, 0x00, 0x00},
+}
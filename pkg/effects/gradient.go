@@ -0,0 +1,201 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Spread indique comment un dégradé se prolonge au-delà de ses bornes
+type Spread int
+
+const (
+	// Pad étend les couleurs extrêmes
+	Pad Spread = iota
+	// Repeat reprend le dégradé depuis le début
+	Repeat
+	// Reflect reprend le dégradé en miroir
+	Reflect
+)
+
+var spreadNames = []string{"Étendre", "Répéter", "Refléter"}
+
+func (s Spread) String() string {
+	if s < 0 || int(s) >= len(spreadNames) {
+		return "Inconnu"
+	}
+	return spreadNames[s]
+}
+
+// ColorStop place une couleur à une position du dégradé, de 0 à 1
+type ColorStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Gradient regroupe les arrêts de couleur et le prolongement communs à tous
+// les dégradés. Comme image.Uniform, chaque dégradé est à la fois une
+// color.Color et une image.Image: il s'utilise partout où une couleur de
+// remplissage est attendue et la couleur est alors prise pixel par pixel
+type Gradient struct {
+	// Stops doivent être triés par position croissante
+	Stops  []ColorStop
+	Spread Spread
+}
+
+// RGBA renvoie la couleur médiane, pour les usages qui n'attendent qu'une
+// couleur unie
+func (g Gradient) RGBA() (r, gr, b, a uint32) {
+	return g.colorAt(0.5).RGBA()
+}
+
+func (g Gradient) ColorModel() color.Model { return color.NRGBA64Model }
+
+// Bounds est illimité: le dégradé couvre tout le plan
+func (g Gradient) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+// colorAt renvoie la couleur à la position t, après prolongement
+func (g Gradient) colorAt(t float64) color.NRGBA64 {
+	if len(g.Stops) == 0 {
+		return color.NRGBA64{}
+	}
+	switch g.Spread {
+	case Repeat:
+		t -= math.Floor(t)
+	case Reflect:
+		t = math.Mod(math.Abs(t), 2)
+		if t > 1 {
+			t = 2 - t
+		}
+	}
+
+	first, last := g.Stops[0], g.Stops[len(g.Stops)-1]
+	if t <= first.Offset {
+		return color.NRGBA64Model.Convert(first.Color).(color.NRGBA64)
+	}
+	if t >= last.Offset {
+		return color.NRGBA64Model.Convert(last.Color).(color.NRGBA64)
+	}
+	i := 1
+	for g.Stops[i].Offset < t {
+		i++
+	}
+	a, b := g.Stops[i-1], g.Stops[i]
+	k := 0.0
+	if span := b.Offset - a.Offset; span > 0 {
+		k = (t - a.Offset) / span
+	}
+	return mixPremultiplied(a.Color, b.Color, k)
+}
+
+// mixPremultiplied interpole deux couleurs en alpha prémultiplié, ce qui
+// évite le liseré sombre vers une couleur transparente
+func mixPremultiplied(c0, c1 color.Color, k float64) color.NRGBA64 {
+	r0, g0, b0, a0 := c0.RGBA()
+	r1, g1, b1, a1 := c1.RGBA()
+	lerp := func(u, v uint32) float64 { return float64(u) + (float64(v)-float64(u))*k }
+	a := lerp(a0, a1)
+	if a <= 0 {
+		return color.NRGBA64{}
+	}
+	unpremul := func(v float64) uint16 {
+		return uint16(math.Round(math.Min(0xffff, v*0xffff/a)))
+	}
+	return color.NRGBA64{
+		R: unpremul(lerp(r0, r1)),
+		G: unpremul(lerp(g0, g1)),
+		B: unpremul(lerp(b0, b1)),
+		A: uint16(math.Round(a)),
+	}
+}
+
+// Les coordonnées des dégradés sont continues: le pixel (x, y) est évalué
+// en son centre (x+0.5, y+0.5)
+
+// LinearGradient varie le long du segment (X0, Y0) → (X1, Y1)
+type LinearGradient struct {
+	Gradient
+	X0, Y0, X1, Y1 float64
+}
+
+func (l *LinearGradient) At(x, y int) color.Color {
+	dx, dy := l.X1-l.X0, l.Y1-l.Y0
+	d2 := dx*dx + dy*dy
+	if d2 == 0 {
+		return l.colorAt(0)
+	}
+	px, py := float64(x)+0.5-l.X0, float64(y)+0.5-l.Y0
+	return l.colorAt((px*dx + py*dy) / d2)
+}
+
+// RadialGradient varie du centre (CX, CY) jusqu'au bord de l'ellipse de
+// rayons RX, RY; RY nul donne un cercle de rayon RX
+type RadialGradient struct {
+	Gradient
+	CX, CY, RX, RY float64
+}
+
+func (r *RadialGradient) At(x, y int) color.Color {
+	ry := r.RY
+	if ry == 0 {
+		ry = r.RX
+	}
+	if r.RX <= 0 || ry <= 0 {
+		return r.colorAt(0)
+	}
+	dx, dy := (float64(x)+0.5-r.CX)/r.RX, (float64(y)+0.5-r.CY)/ry
+	return r.colorAt(math.Hypot(dx, dy))
+}
+
+// ConicGradient tourne autour de (CX, CY) dans le sens des aiguilles d'une
+// montre, en partant de l'angle Angle (degrés, 0 vers la droite). Le
+// prolongement n'a d'effet que sur des arrêts n'occupant pas tout le tour
+type ConicGradient struct {
+	Gradient
+	CX, CY, Angle float64
+}
+
+func (c *ConicGradient) At(x, y int) color.Color {
+	// L'axe y de l'image pointe vers le bas: atan2 croît dans le sens horaire
+	a := math.Atan2(float64(y)+0.5-c.CY, float64(x)+0.5-c.CX)*180/math.Pi - c.Angle
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	return c.colorAt(a / 360)
+}
+
+// GradientOverlayEffect compose un dégradé sur toute l'image: ciel, fondu
+// ou vignettage (dégradé radial vers du noir en mode Produit)
+type GradientOverlayEffect struct {
+	// Fill est la source de couleur, en général un dégradé
+	Fill        image.Image
+	Compositing Compositing
+}
+
+func (g *GradientOverlayEffect) Name() string { return "Dégradé" }
+func (g *GradientOverlayEffect) Description() string {
+	return "Superpose un dégradé linéaire, radial ou conique à toute l'image"
+}
+
+func (g *GradientOverlayEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	if g.Fill == nil {
+		return result
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			src := color.NRGBA64Model.Convert(g.Fill.At(x, y)).(color.NRGBA64)
+			g.Compositing.compose(result, x, y, src, 1)
+		}
+	}
+	return result
+}
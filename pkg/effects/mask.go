@@ -0,0 +1,173 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Les masques de sélection sont des *image.Alpha aux bornes de l'image:
+// 255 pour un pixel sélectionné, 0 pour un pixel intact, les valeurs
+// intermédiaires mélangeant l'original et le résultat de l'effet
+
+// ShapeMask sélectionne l'intérieur d'une forme, bords anticrénelés
+func ShapeMask(bounds image.Rectangle, shape Shape, rule FillRule) *image.Alpha {
+	mask := image.NewAlpha(bounds)
+	var polys [][]point
+	for _, sp := range shape.subpaths() {
+		if len(sp.points) > 2 {
+			polys = append(polys, sp.points)
+		}
+	}
+	rasterizePolygons(polys, bounds, rule, true, func(x, y int, coverage float64) {
+		mask.SetAlpha(x, y, color.Alpha{unit8(coverage)})
+	})
+	return mask
+}
+
+// RectMask sélectionne le rectangle r
+func RectMask(bounds, r image.Rectangle) *image.Alpha {
+	return ShapeMask(bounds, Rectangle(r.Min.X, r.Min.Y, r.Dx(), r.Dy()), NonZero)
+}
+
+// EllipseMask sélectionne l'ellipse de centre (cx, cy) et de rayons rx, ry
+func EllipseMask(bounds image.Rectangle, cx, cy, rx, ry int) *image.Alpha {
+	return ShapeMask(bounds, Ellipse(cx, cy, rx, ry), NonZero)
+}
+
+// PolygonMask sélectionne l'intérieur du polygone; les zones recoupées
+// suivent la règle pair-impair, comme un lasso
+func PolygonMask(bounds image.Rectangle, points []image.Point) *image.Alpha {
+	return ShapeMask(bounds, Polyline(points, true), EvenOdd)
+}
+
+// MagicWandMask sélectionne les pixels de couleur proche de celle de (x, y),
+// selon les mêmes réglages que le pot de peinture
+func MagicWandMask(img image.Image, x, y int, tolerance float64, distance ColorDistance, conn Connectivity, global bool) *image.Alpha {
+	bounds := img.Bounds()
+	mask := image.NewAlpha(bounds)
+	f := &FloodFillEffect{X: x, Y: y, Tolerance: tolerance, Distance: distance, Connectivity: conn, Global: global}
+	region := f.region(img, bounds)
+	w := bounds.Dx()
+	for i, in := range region {
+		if in {
+			mask.SetAlpha(bounds.Min.X+i%w, bounds.Min.Y+i/w, color.Alpha{255})
+		}
+	}
+	return mask
+}
+
+// LuminanceMask sélectionne les pixels dont la luminance (0 à 255, même
+// pondération que les niveaux de gris) est comprise entre lo et hi
+func LuminanceMask(img image.Image, lo, hi float64) *image.Alpha {
+	bounds := img.Bounds()
+	mask := image.NewAlpha(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			lum := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+			if lum >= lo && lum <= hi {
+				mask.SetAlpha(x, y, color.Alpha{255})
+			}
+		}
+	}
+	return mask
+}
+
+// InvertMask échange pixels sélectionnés et intacts
+func InvertMask(mask *image.Alpha) *image.Alpha {
+	out := image.NewAlpha(mask.Rect)
+	for i, a := range mask.Pix {
+		out.Pix[i] = 255 - a
+	}
+	return out
+}
+
+// FeatherMask adoucit le bord de la sélection par un flou gaussien: la
+// transition s'étale sur environ radius pixels de part et d'autre du bord
+func FeatherMask(mask *image.Alpha, radius float64) *image.Alpha {
+	if radius <= 0 {
+		return mask
+	}
+	half := int(math.Ceil(radius))
+	sigma := radius / 3
+	kernel := make([]float64, 2*half+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - half)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	// Flou séparable: horizontal puis vertical, bords prolongés
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	tmp := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		row := mask.Pix[y*mask.Stride:]
+		for x := 0; x < w; x++ {
+			var v float64
+			for k, c := range kernel {
+				sx := minInt(maxInt(x+k-half, 0), w-1)
+				v += c * float64(row[sx])
+			}
+			tmp[y*w+x] = v
+		}
+	}
+	out := image.NewAlpha(mask.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var v float64
+			for k, c := range kernel {
+				sy := minInt(maxInt(y+k-half, 0), h-1)
+				v += c * tmp[sy*w+x]
+			}
+			out.Pix[y*out.Stride+x] = uint8(math.Round(math.Min(255, v)))
+		}
+	}
+	return out
+}
+
+// MaskedEffect limite un effet à une sélection: hors du masque l'image est
+// intacte, et les valeurs intermédiaires fondent le résultat dans l'original
+type MaskedEffect struct {
+	Effect Effect
+	Mask   *image.Alpha
+}
+
+func (m *MaskedEffect) Name() string { return m.Effect.Name() + " (sélection)" }
+func (m *MaskedEffect) Description() string {
+	return m.Effect.Description() + ", limité à la sélection"
+}
+
+func (m *MaskedEffect) Apply(img image.Image) image.Image {
+	applied := m.Effect.Apply(img)
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var k float64
+			if m.Mask != nil && (image.Point{x, y}.In(m.Mask.Rect)) {
+				k = float64(m.Mask.AlphaAt(x, y).A) / 255
+			}
+			if k == 0 || !(image.Point{x, y}.In(applied.Bounds())) {
+				result.Set(x, y, img.At(x, y))
+				continue
+			}
+			if k == 1 {
+				result.Set(x, y, applied.At(x, y))
+				continue
+			}
+			// Mélange en couleurs prémultipliées
+			r0, g0, b0, a0 := img.At(x, y).RGBA()
+			r1, g1, b1, a1 := applied.At(x, y).RGBA()
+			mix := func(u, v uint32) uint16 {
+				return uint16(math.Round(float64(u) + (float64(v)-float64(u))*k))
+			}
+			result.SetRGBA64(x, y, color.RGBA64{mix(r0, r1), mix(g0, g1), mix(b0, b1), mix(a0, a1)})
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package effects
+
+import "math"
+
+// Tolérance d'aplatissement des courbes, en pixels
+const flatness = 0.1
+
+// Path est un tracé vectoriel composé de sous-chemins de segments et de
+// courbes de Bézier. Les coordonnées sont continues: le pixel (x, y) couvre
+// le carré [x, x+1) × [y, y+1). La valeur zéro est un chemin vide
+type Path struct {
+	subs    []subpath
+	start   point
+	current point
+	// open indique qu'un sous-chemin est en cours et accepte des segments
+	open bool
+}
+
+// MoveTo commence un nouveau sous-chemin au point (x, y)
+func (p *Path) MoveTo(x, y float64) {
+	p.start = point{x, y}
+	p.current = p.start
+	p.subs = append(p.subs, subpath{points: []point{p.start}})
+	p.open = true
+}
+
+// LineTo ajoute un segment jusqu'au point (x, y)
+func (p *Path) LineTo(x, y float64) {
+	p.ensureOpen()
+	p.add(point{x, y})
+}
+
+// QuadTo ajoute une courbe de Bézier quadratique de point de contrôle (cx, cy)
+func (p *Path) QuadTo(cx, cy, x, y float64) {
+	p.ensureOpen()
+	p0, p1, p2 := p.current, point{cx, cy}, point{x, y}
+	dd := math.Hypot(p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y)
+	n := segmentsFor(dd / 4)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		p.add(point{
+			u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+			u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+		})
+	}
+}
+
+// CubicTo ajoute une courbe de Bézier cubique de points de contrôle
+// (c1x, c1y) et (c2x, c2y)
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float64) {
+	p.ensureOpen()
+	p0, p1, p2, p3 := p.current, point{c1x, c1y}, point{c2x, c2y}, point{x, y}
+	dd := math.Max(
+		math.Hypot(p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y),
+		math.Hypot(p1.X-2*p2.X+p3.X, p1.Y-2*p2.Y+p3.Y),
+	)
+	n := segmentsFor(dd * 3 / 4)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		p.add(point{
+			a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+			a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+		})
+	}
+}
+
+// Close ferme le sous-chemin courant en revenant à son point de départ
+func (p *Path) Close() {
+	if !p.open {
+		return
+	}
+	p.subs[len(p.subs)-1].closed = true
+	p.current = p.start
+	p.open = false
+}
+
+func (p *Path) subpaths() []subpath { return p.subs }
+
+// ensureOpen reprend au dernier point après Close, comme en SVG
+func (p *Path) ensureOpen() {
+	if !p.open {
+		p.MoveTo(p.current.X, p.current.Y)
+	}
+}
+
+func (p *Path) add(pt point) {
+	last := &p.subs[len(p.subs)-1]
+	last.points = append(last.points, pt)
+	p.current = pt
+}
+
+// segmentsFor renvoie le nombre de segments pour lequel l'écart entre la
+// courbe et sa corde reste sous la tolérance, l'erreur décroissant en 1/n²
+func segmentsFor(deviation float64) int {
+	n := int(math.Ceil(math.Sqrt(deviation / flatness)))
+	if n < 1 {
+		return 1
+	}
+	return minInt(n, 1000)
+}
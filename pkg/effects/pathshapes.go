@@ -0,0 +1,117 @@
+package effects
+
+import "math"
+
+// Constante des arcs de Bézier cubiques approchant un quart de cercle
+const kappa = 0.5522847498
+
+// Comme les autres formes, les coordonnées entières désignent des centres
+// de pixels
+
+func center(x, y int) (float64, float64) {
+	return float64(x) + 0.5, float64(y) + 0.5
+}
+
+// ellipsePath construit une ellipse en quatre arcs de Bézier cubiques
+func ellipsePath(cx, cy, rx, ry float64) *Path {
+	kx, ky := rx*kappa, ry*kappa
+	p := &Path{}
+	p.MoveTo(cx+rx, cy)
+	p.CubicTo(cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry)
+	p.CubicTo(cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy)
+	p.CubicTo(cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry)
+	p.CubicTo(cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy)
+	p.Close()
+	return p
+}
+
+// RoundedRect renvoie le rectangle de w × h pixels depuis (x, y) dont les
+// coins sont arrondis de rayon radius
+func RoundedRect(x, y, w, h, radius int) *Path {
+	x0, y0 := float64(x), float64(y)
+	x1, y1 := x0+float64(w), y0+float64(h)
+	r := math.Min(float64(radius), math.Min(float64(w), float64(h))/2)
+	k := r * (1 - kappa)
+
+	p := &Path{}
+	p.MoveTo(x0+r, y0)
+	p.LineTo(x1-r, y0)
+	p.CubicTo(x1-k, y0, x1, y0+k, x1, y0+r)
+	p.LineTo(x1, y1-r)
+	p.CubicTo(x1, y1-k, x1-k, y1, x1-r, y1)
+	p.LineTo(x0+r, y1)
+	p.CubicTo(x0+k, y1, x0, y1-k, x0, y1-r)
+	p.LineTo(x0, y0+r)
+	p.CubicTo(x0, y0+k, x0+k, y0, x0+r, y0)
+	p.Close()
+	return p
+}
+
+// RegularPolygon renvoie le polygone régulier à sides côtés inscrit dans le
+// cercle de rayon radius, un sommet pointant vers le haut
+func RegularPolygon(cx, cy, radius, sides int) *Path {
+	x, y := center(cx, cy)
+	p := &Path{}
+	for i := 0; i < sides; i++ {
+		a := -math.Pi/2 + 2*math.Pi*float64(i)/float64(sides)
+		px, py := x+float64(radius)*math.Cos(a), y+float64(radius)*math.Sin(a)
+		if i == 0 {
+			p.MoveTo(px, py)
+		} else {
+			p.LineTo(px, py)
+		}
+	}
+	p.Close()
+	return p
+}
+
+// Star renvoie une étoile à branches pointes dont les sommets alternent
+// entre les rayons outer et inner, une pointe vers le haut
+func Star(cx, cy, outer, inner, branches int) *Path {
+	x, y := center(cx, cy)
+	p := &Path{}
+	for i := 0; i < 2*branches; i++ {
+		r := float64(outer)
+		if i%2 == 1 {
+			r = float64(inner)
+		}
+		a := -math.Pi/2 + math.Pi*float64(i)/float64(branches)
+		if i == 0 {
+			p.MoveTo(x+r*math.Cos(a), y+r*math.Sin(a))
+		} else {
+			p.LineTo(x+r*math.Cos(a), y+r*math.Sin(a))
+		}
+	}
+	p.Close()
+	return p
+}
+
+// Arrow renvoie une flèche de (x1, y1) vers (x2, y2) dont le corps a
+// l'épaisseur width; la pointe mesure trois fois cette épaisseur
+func Arrow(x1, y1, x2, y2, width int) *Path {
+	ax, ay := center(x1, y1)
+	bx, by := center(x2, y2)
+	dx, dy := bx-ax, by-ay
+	length := math.Hypot(dx, dy)
+	p := &Path{}
+	if length == 0 {
+		return p
+	}
+	ux, uy := dx/length, dy/length
+	nx, ny := -uy, ux
+
+	hw := float64(width) / 2
+	head := math.Min(3*float64(width), length)
+	// Base de la pointe
+	hx, hy := bx-ux*head, by-uy*head
+
+	p.MoveTo(ax+nx*hw, ay+ny*hw)
+	p.LineTo(hx+nx*hw, hy+ny*hw)
+	p.LineTo(hx+nx*3*hw, hy+ny*3*hw)
+	p.LineTo(bx, by)
+	p.LineTo(hx-nx*3*hw, hy-ny*3*hw)
+	p.LineTo(hx-nx*hw, hy-ny*hw)
+	p.LineTo(ax-nx*hw, ay-ny*hw)
+	p.Close()
+	return p
+}
@@ -0,0 +1,177 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// point est un sommet en coordonnées flottantes: le pixel (x, y) couvre le
+// carré [x, x+1) × [y, y+1), son centre est en (x+0.5, y+0.5)
+type point struct {
+	X, Y float64
+}
+
+// Nombre de sous-lignes échantillonnées par ligne de pixels en anticrénelage
+const subSamples = 4
+
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// FillRule détermine l'intérieur d'un contour qui se recoupe
+type FillRule int
+
+const (
+	// NonZero remplit les points dont l'indice d'enroulement est non nul
+	NonZero FillRule = iota
+	// EvenOdd remplit les points entourés un nombre impair de fois
+	EvenOdd
+)
+
+func (r FillRule) inside(winding int) bool {
+	if r == EvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
+
+// rasterizePolygons parcourt les pixels couverts par les contours fermés
+// selon la règle de remplissage et appelle plot avec la couverture de chaque
+// pixel; sans anticrénelage, un pixel est couvert si son centre est intérieur
+func rasterizePolygons(polys [][]point, bounds image.Rectangle, rule FillRule, antiAlias bool, plot func(x, y int, coverage float64)) {
+	var edges []edge
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, poly := range polys {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			if a.Y == b.Y {
+				continue
+			}
+			e := edge{a.X, a.Y, b.X, b.Y, 1}
+			if a.Y > b.Y {
+				e = edge{b.X, b.Y, a.X, a.Y, -1}
+			}
+			edges = append(edges, e)
+			minY = math.Min(minY, e.y0)
+			maxY = math.Max(maxY, e.y1)
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+
+	y0 := maxInt(bounds.Min.Y, int(math.Floor(minY)))
+	y1 := minInt(bounds.Max.Y, int(math.Ceil(maxY)))
+	samples := 1
+	if antiAlias {
+		samples = subSamples
+	}
+	width := bounds.Dx()
+	cover := make([]float64, width+1)
+	var xs []crossing
+
+	for y := y0; y < y1; y++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		touched := false
+
+		for s := 0; s < samples; s++ {
+			sy := float64(y) + (float64(s)+0.5)/float64(samples)
+			xs = xs[:0]
+			for _, e := range edges {
+				if e.y0 > sy {
+					break
+				}
+				if sy >= e.y1 {
+					continue
+				}
+				x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+				xs = append(xs, crossing{x, e.dir})
+			}
+			sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+
+			winding := 0
+			for i := 0; i+1 < len(xs); i++ {
+				winding += xs[i].dir
+				if !rule.inside(winding) {
+					continue
+				}
+				a := xs[i].x - float64(bounds.Min.X)
+				b := xs[i+1].x - float64(bounds.Min.X)
+				if antiAlias {
+					addSpan(cover, a, b, 1/float64(samples))
+				} else {
+					// Pixels dont le centre est dans [a, b)
+					for x := maxInt(0, int(math.Ceil(a-0.5))); x < width && float64(x)+0.5 < b; x++ {
+						cover[x] = 1
+					}
+				}
+				touched = true
+			}
+		}
+
+		if !touched {
+			continue
+		}
+		for x := 0; x < width; x++ {
+			if cover[x] > 0 {
+				plot(bounds.Min.X+x, y, math.Min(cover[x], 1))
+			}
+		}
+	}
+}
+
+// addSpan ajoute la couverture horizontale exacte de l'intervalle [a, b)
+func addSpan(cover []float64, a, b, weight float64) {
+	width := float64(len(cover) - 1)
+	a, b = math.Max(a, 0), math.Min(b, width)
+	if a >= b {
+		return
+	}
+	ia, ib := int(a), int(b)
+	if ia == ib {
+		cover[ia] += (b - a) * weight
+		return
+	}
+	cover[ia] += (float64(ia+1) - a) * weight
+	for x := ia + 1; x < ib; x++ {
+		cover[x] += weight
+	}
+	if ib < len(cover)-1 {
+		cover[ib] += (b - float64(ib)) * weight
+	}
+}
+
+// blendPixel mélange la couleur sur le pixel selon la couverture (0 à 1)
+func blendPixel(img *image.RGBA, x, y int, c color.Color, coverage float64) {
+	if !(image.Point{x, y}.In(img.Bounds())) || coverage <= 0 {
+		return
+	}
+	r, g, b, a := c.RGBA()
+	if coverage >= 1 && a == 0xffff {
+		img.Set(x, y, c)
+		return
+	}
+
+	// Composition "source sur destination" en couleurs prémultipliées
+	k := coverage
+	sa := float64(a) * k
+	dst := img.RGBAAt(x, y)
+	inv := 1 - sa/0xffff
+	img.SetRGBA(x, y, color.RGBA{
+		R: uint8((float64(r)*k + float64(dst.R)*257*inv) / 257),
+		G: uint8((float64(g)*k + float64(dst.G)*257*inv) / 257),
+		B: uint8((float64(b)*k + float64(dst.B)*257*inv) / 257),
+		A: uint8((sa + float64(dst.A)*257*inv) / 257),
+	})
+}
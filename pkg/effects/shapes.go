@@ -1,156 +1,206 @@
-package effects
-
-import (
-	"image"
-	"image/color"
-)
-
-type SquareEffect struct {
-	X, Y, Size int
-	Color      color.Color
-}
-
-func (s *SquareEffect) Name() string        { return "Carré" }
-func (s *SquareEffect) Description() string { return "Dessine un carré rempli" }
-func (s *SquareEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-	for y := s.Y; y < s.Y+s.Size && y < bounds.Max.Y; y++ {
-		for x := s.X; x < s.X+s.Size && x < bounds.Max.X; x++ {
-			if x >= bounds.Min.X && y >= bounds.Min.Y {
-				result.Set(x, y, s.Color)
-			}
-		}
-	}
-	return result
-}
-
-type CircleEffect struct {
-	CenterX, CenterY, Radius int
-	Color                    color.Color
-}
-
-func (c *CircleEffect) Name() string        { return "Cercle" }
-func (c *CircleEffect) Description() string { return "Dessine un cercle rempli" }
-func (c *CircleEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-	drawFilledCircle(result, c.CenterX, c.CenterY, c.Radius, c.Color)
-	return result
-}
-
-func drawFilledCircle(img *image.RGBA, centerX, centerY, radius int, color color.Color) {
-	bounds := img.Bounds()
-	for y := centerY - radius; y <= centerY + radius; y++ {
-		for x := centerX - radius; x <= centerX + radius; x++ {
-			dx := x - centerX
-			dy := y - centerY
-			if dx*dx + dy*dy <= radius*radius {
-				if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					img.Set(x, y, color)
-				}
-			}
-		}
-	}
-}
-
-type TriangleEffect struct {
-	X1, Y1, X2, Y2, X3, Y3 int
-	Color                   color.Color
-}
-
-func (t *TriangleEffect) Name() string        { return "Triangle" }
-func (t *TriangleEffect) Description() string { return "Dessine un triangle rempli" }
-func (t *TriangleEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-	drawFilledTriangle(result, t.X1, t.Y1, t.X2, t.Y2, t.X3, t.Y3, t.Color)
-	return result
-}
-
-func drawFilledTriangle(img *image.RGBA, x1, y1, x2, y2, x3, y3 int, color color.Color) {
-	bounds := img.Bounds()
-	minX := minInt(minInt(x1, x2), x3)
-	maxX := maxInt(maxInt(x1, x2), x3)
-	minY := minInt(minInt(y1, y2), y3)
-	maxY := maxInt(maxInt(y1, y2), y3)
-	minX = maxInt(minX, bounds.Min.X)
-	maxX = minInt(maxX, bounds.Max.X-1)
-	minY = maxInt(minY, bounds.Min.Y)
-	maxY = minInt(maxY, bounds.Max.Y-1)
-	for y := minY; y <= maxY; y++ {
-		for x := minX; x <= maxX; x++ {
-			if pointInTriangle(x, y, x1, y1, x2, y2, x3, y3) {
-				img.Set(x, y, color)
-			}
-		}
-	}
-}
-
-func pointInTriangle(px, py, x1, y1, x2, y2, x3, y3 int) bool {
-	denom := float64((y2-y3)*(x1-x3) + (x3-x2)*(y1-y3))
-	if denom == 0 {
-		return false
-	}
-	w1 := float64((y2-y3)*(px-x3) + (x3-x2)*(py-y3)) / denom
-	w2 := float64((y3-y1)*(px-x3) + (x1-x3)*(py-y3)) / denom
-	w3 := 1.0 - w1 - w2
-	return w1 >= 0 && w2 >= 0 && w3 >= 0
-}
-
-type LineEffect struct {
-	X1, Y1, X2, Y2 int
-	Color           color.Color
-}
-
-func (l *LineEffect) Name() string        { return "Ligne" }
-func (l *LineEffect) Description() string { return "Dessine une ligne droite" }
-func (l *LineEffect) Apply(img image.Image) image.Image {
-	bounds := img.Bounds()
-	result := image.NewRGBA(bounds)
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			result.Set(x, y, img.At(x, y))
-		}
-	}
-	drawLine(result, l.X1, l.Y1, l.X2, l.Y2, l.Color)
-	return result
-}
-
-func drawLine(img *image.RGBA, x1, y1, x2, y2 int, color color.Color) {
-	bounds := img.Bounds()
-	dx := absInt(x2 - x1)
-	dy := absInt(y2 - y1)
-	var sx, sy int
-	if x1 < x2 { sx = 1 } else { sx = -1 }
-	if y1 < y2 { sy = 1 } else { sy = -1 }
-	err := dx - dy
-	for {
-		if x1 >= bounds.Min.X && x1 < bounds.Max.X && y1 >= bounds.Min.Y && y1 < bounds.Max.Y {
-			img.Set(x1, y1, color)
-		}
-		if x1 == x2 && y1 == y2 { break }
-		e2 := 2 * err
-		if e2 > -dy { err -= dy; x1 += sx }
-		if e2 < dx { err += dx; y1 += sy }
-	}
-}
-
-func minInt(a, b int) int { if a < b { return a }; return b }
-func maxInt(a, b int) int { if a > b { return a }; return b }
+package effects
+
+import (
+	"image"
+	"image/color"
+)
+
+type SquareEffect struct {
+	X, Y, Size int
+	Color      color.Color
+	// AntiAlias passe par le rasteriseur à couverture (bords alignés sur la
+	// grille: le résultat est identique, utile pour la cohérence des réglages)
+	AntiAlias bool
+	// Compositing règle opacité, opérateur et mode de fusion; nil pour un
+	// tracé opaque
+	Compositing *Compositing
+}
+
+func (s *SquareEffect) Name() string        { return "Carré" }
+func (s *SquareEffect) Description() string { return "Dessine un carré rempli" }
+func (s *SquareEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	paintShape(result, s.Color, s.Compositing, func(dst *image.RGBA, c color.Color) {
+		if s.AntiAlias {
+			x0, y0 := float64(s.X), float64(s.Y)
+			x1, y1 := x0+float64(s.Size), y0+float64(s.Size)
+			square := []point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+			rasterizePolygons([][]point{square}, bounds, NonZero, true, func(x, y int, coverage float64) {
+				blendPixel(dst, x, y, c, coverage)
+			})
+			return
+		}
+		for y := s.Y; y < s.Y+s.Size && y < bounds.Max.Y; y++ {
+			for x := s.X; x < s.X+s.Size && x < bounds.Max.X; x++ {
+				if x >= bounds.Min.X && y >= bounds.Min.Y {
+					dst.Set(x, y, c)
+				}
+			}
+		}
+	})
+	return result
+}
+
+type CircleEffect struct {
+	CenterX, CenterY, Radius int
+	Color                    color.Color
+	// AntiAlias adoucit le bord selon la couverture analytique du cercle
+	AntiAlias bool
+	// Compositing règle opacité, opérateur et mode de fusion; nil pour un
+	// tracé opaque
+	Compositing *Compositing
+}
+
+func (c *CircleEffect) Name() string        { return "Cercle" }
+func (c *CircleEffect) Description() string { return "Dessine un cercle rempli" }
+func (c *CircleEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	paintShape(result, c.Color, c.Compositing, func(dst *image.RGBA, col color.Color) {
+		if c.AntiAlias {
+			drawFilledCircleAA(dst, c.CenterX, c.CenterY, c.Radius, col)
+		} else {
+			drawFilledCircle(dst, c.CenterX, c.CenterY, c.Radius, col)
+		}
+	})
+	return result
+}
+
+func drawFilledCircle(img *image.RGBA, centerX, centerY, radius int, color color.Color) {
+	bounds := img.Bounds()
+	for y := centerY - radius; y <= centerY + radius; y++ {
+		for x := centerX - radius; x <= centerX + radius; x++ {
+			dx := x - centerX
+			dy := y - centerY
+			if dx*dx + dy*dy <= radius*radius {
+				if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
+					img.Set(x, y, color)
+				}
+			}
+		}
+	}
+}
+
+type TriangleEffect struct {
+	X1, Y1, X2, Y2, X3, Y3 int
+	Color                   color.Color
+	// AntiAlias suréchantillonne les bords du triangle
+	AntiAlias bool
+	// Compositing règle opacité, opérateur et mode de fusion; nil pour un
+	// tracé opaque
+	Compositing *Compositing
+}
+
+func (t *TriangleEffect) Name() string        { return "Triangle" }
+func (t *TriangleEffect) Description() string { return "Dessine un triangle rempli" }
+func (t *TriangleEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	paintShape(result, t.Color, t.Compositing, func(dst *image.RGBA, c color.Color) {
+		if t.AntiAlias {
+			drawFilledTriangleAA(dst, t.X1, t.Y1, t.X2, t.Y2, t.X3, t.Y3, c)
+		} else {
+			drawFilledTriangle(dst, t.X1, t.Y1, t.X2, t.Y2, t.X3, t.Y3, c)
+		}
+	})
+	return result
+}
+
+func drawFilledTriangle(img *image.RGBA, x1, y1, x2, y2, x3, y3 int, color color.Color) {
+	bounds := img.Bounds()
+	minX := minInt(minInt(x1, x2), x3)
+	maxX := maxInt(maxInt(x1, x2), x3)
+	minY := minInt(minInt(y1, y2), y3)
+	maxY := maxInt(maxInt(y1, y2), y3)
+	minX = maxInt(minX, bounds.Min.X)
+	maxX = minInt(maxX, bounds.Max.X-1)
+	minY = maxInt(minY, bounds.Min.Y)
+	maxY = minInt(maxY, bounds.Max.Y-1)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if pointInTriangle(x, y, x1, y1, x2, y2, x3, y3) {
+				img.Set(x, y, color)
+			}
+		}
+	}
+}
+
+func pointInTriangle(px, py, x1, y1, x2, y2, x3, y3 int) bool {
+	denom := float64((y2-y3)*(x1-x3) + (x3-x2)*(y1-y3))
+	if denom == 0 {
+		return false
+	}
+	w1 := float64((y2-y3)*(px-x3) + (x3-x2)*(py-y3)) / denom
+	w2 := float64((y3-y1)*(px-x3) + (x1-x3)*(py-y3)) / denom
+	w3 := 1.0 - w1 - w2
+	return w1 >= 0 && w2 >= 0 && w3 >= 0
+}
+
+type LineEffect struct {
+	X1, Y1, X2, Y2 int
+	Color           color.Color
+	// AntiAlias trace la ligne avec l'algorithme de Wu
+	AntiAlias bool
+	// Compositing règle opacité, opérateur et mode de fusion; nil pour un
+	// tracé opaque
+	Compositing *Compositing
+}
+
+func (l *LineEffect) Name() string        { return "Ligne" }
+func (l *LineEffect) Description() string { return "Dessine une ligne droite" }
+func (l *LineEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	paintShape(result, l.Color, l.Compositing, func(dst *image.RGBA, c color.Color) {
+		if l.AntiAlias {
+			drawLineAA(dst, l.X1, l.Y1, l.X2, l.Y2, c)
+		} else {
+			drawLine(dst, l.X1, l.Y1, l.X2, l.Y2, c)
+		}
+	})
+	return result
+}
+
+func drawLine(img *image.RGBA, x1, y1, x2, y2 int, color color.Color) {
+	bounds := img.Bounds()
+	dx := absInt(x2 - x1)
+	dy := absInt(y2 - y1)
+	var sx, sy int
+	if x1 < x2 { sx = 1 } else { sx = -1 }
+	if y1 < y2 { sy = 1 } else { sy = -1 }
+	err := dx - dy
+	for {
+		if x1 >= bounds.Min.X && x1 < bounds.Max.X && y1 >= bounds.Min.Y && y1 < bounds.Max.Y {
+			img.Set(x1, y1, color)
+		}
+		if x1 == x2 && y1 == y2 { break }
+		e2 := 2 * err
+		if e2 > -dy { err -= dy; x1 += sx }
+		if e2 < dx { err += dx; y1 += sy }
+	}
+}
+
+func minInt(a, b int) int { if a < b { return a }; return b }
+func maxInt(a, b int) int { if a > b { return a }; return b }
 func absInt(x int) int { if x < 0 { return -x }; return x } 
\ No newline at end of file
@@ -0,0 +1,300 @@
+package effects
+
+import (
+	"image/color"
+	"math"
+)
+
+// LineCap est la forme des extrémités d'un trait ouvert
+type LineCap int
+
+const (
+	// ButtCap arrête le trait net sur l'extrémité
+	ButtCap LineCap = iota
+	// RoundCap termine le trait par un demi-disque
+	RoundCap
+	// SquareCap prolonge le trait d'une demi-épaisseur
+	SquareCap
+)
+
+// LineJoin est la forme des angles entre deux segments
+type LineJoin int
+
+const (
+	// MiterJoin prolonge les bords jusqu'à leur intersection
+	MiterJoin LineJoin = iota
+	// RoundJoin arrondit l'angle
+	RoundJoin
+	// BevelJoin coupe l'angle
+	BevelJoin
+)
+
+// Stroke décrit le tracé d'un contour
+type Stroke struct {
+	Width float64
+	Color color.Color
+	// Dash alterne longueurs de trait et d'espace; vide pour un trait continu
+	Dash []float64
+	Cap  LineCap
+	Join LineJoin
+	// MiterLimit borne le rapport longueur d'onglet / épaisseur (4 par défaut)
+	MiterLimit float64
+}
+
+// subpath est une suite de points, fermée ou non
+type subpath struct {
+	points []point
+	closed bool
+	// dir oriente les extrémités d'un morceau réduit à un point, comme un
+	// trait de longueur nulle dans un pointillé
+	dir point
+}
+
+// strokePolygons renvoie les polygones couvrant le tracé des sous-chemins;
+// tous sont orientés dans le même sens pour que la règle non nulle en
+// fasse l'union
+func strokePolygons(paths []subpath, s *Stroke) [][]point {
+	hw := s.Width / 2
+	if hw <= 0 {
+		return nil
+	}
+	var polys [][]point
+	add := func(p []point) {
+		if len(p) < 3 {
+			return
+		}
+		if signedArea(p) < 0 {
+			for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+				p[i], p[j] = p[j], p[i]
+			}
+		}
+		polys = append(polys, p)
+	}
+
+	for _, sp := range paths {
+		pts := dedupe(sp.points)
+		if len(pts) == 0 {
+			continue
+		}
+		pieces := []subpath{{points: pts, closed: sp.closed && len(pts) > 2}}
+		if dashTotal(s.Dash) > 0 {
+			pieces = dashPolyline(pts, sp.closed, s.Dash)
+		}
+		for _, piece := range pieces {
+			strokePolyline(dedupe(piece.points), piece.closed, piece.dir, hw, s, add)
+		}
+	}
+	return polys
+}
+
+func strokePolyline(pts []point, closed bool, dir point, hw float64, s *Stroke, add func([]point)) {
+	n := len(pts)
+	// Point isolé: seules les extrémités rondes ou carrées sont visibles; le
+	// carré suit dir s'il est connu, les axes sinon
+	if n == 1 {
+		p := pts[0]
+		switch {
+		case s.Cap == RoundCap:
+			add(circlePolygon(p, hw))
+		case s.Cap == SquareCap && dir != (point{}):
+			addCap(point{p.X - dir.X, p.Y - dir.Y}, p, hw, s.Cap, add)
+			addCap(point{p.X + dir.X, p.Y + dir.Y}, p, hw, s.Cap, add)
+		case s.Cap == SquareCap:
+			add([]point{{p.X - hw, p.Y - hw}, {p.X + hw, p.Y - hw}, {p.X + hw, p.Y + hw}, {p.X - hw, p.Y + hw}})
+		}
+		return
+	}
+
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		a, b := pts[i], pts[(i+1)%n]
+		nx, ny := normal(a, b)
+		add([]point{
+			{a.X + nx*hw, a.Y + ny*hw},
+			{b.X + nx*hw, b.Y + ny*hw},
+			{b.X - nx*hw, b.Y - ny*hw},
+			{a.X - nx*hw, a.Y - ny*hw},
+		})
+	}
+
+	// Jonctions aux sommets intérieurs (à tous les sommets si fermé)
+	for i := 0; i < n; i++ {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		prev, cur, next := pts[(i+n-1)%n], pts[i], pts[(i+1)%n]
+		join(prev, cur, next, hw, s, add)
+	}
+
+	if !closed {
+		addCap(pts[1], pts[0], hw, s.Cap, add)
+		addCap(pts[n-2], pts[n-1], hw, s.Cap, add)
+	}
+}
+
+// join ajoute la pièce qui comble l'angle extérieur au sommet cur
+func join(prev, cur, next point, hw float64, s *Stroke, add func([]point)) {
+	n1x, n1y := normal(prev, cur)
+	n2x, n2y := normal(cur, next)
+	cross := (cur.X-prev.X)*(next.Y-cur.Y) - (cur.Y-prev.Y)*(next.X-cur.X)
+	if math.Abs(cross) < 1e-9 && n1x*n2x+n1y*n2y > 0 {
+		return
+	}
+	if s.Join == RoundJoin {
+		add(circlePolygon(cur, hw))
+		return
+	}
+
+	// Côté extérieur du virage
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	p1 := point{cur.X + side*n1x*hw, cur.Y + side*n1y*hw}
+	p2 := point{cur.X + side*n2x*hw, cur.Y + side*n2y*hw}
+
+	if s.Join == MiterJoin {
+		limit := s.MiterLimit
+		if limit <= 0 {
+			limit = 4
+		}
+		// Bissectrice des normales: l'onglet est à hw / cos(θ/2)
+		mx, my := n1x+n2x, n1y+n2y
+		ml := math.Hypot(mx, my)
+		if ml > 1e-9 {
+			cosHalf := ml / 2
+			if 1/cosHalf <= limit {
+				scale := side * hw / cosHalf / ml
+				add([]point{cur, p1, {cur.X + mx*scale, cur.Y + my*scale}, p2})
+				return
+			}
+		}
+	}
+	add([]point{cur, p1, p2})
+}
+
+// addCap ajoute l'extrémité au point end du segment from → end
+func addCap(from, end point, hw float64, cap LineCap, add func([]point)) {
+	switch cap {
+	case RoundCap:
+		add(circlePolygon(end, hw))
+	case SquareCap:
+		dx, dy := end.X-from.X, end.Y-from.Y
+		l := math.Hypot(dx, dy)
+		dx, dy = dx/l*hw, dy/l*hw
+		nx, ny := -dy, dx
+		add([]point{
+			{end.X + nx, end.Y + ny},
+			{end.X + dx + nx, end.Y + dy + ny},
+			{end.X + dx - nx, end.Y + dy - ny},
+			{end.X - nx, end.Y - ny},
+		})
+	}
+}
+
+// dashPolyline découpe la polyligne selon le motif de pointillés
+func dashPolyline(pts []point, closed bool, dash []float64) []subpath {
+	if closed {
+		pts = append(append([]point(nil), pts...), pts[0])
+	}
+	var out []subpath
+	index, remaining, on := 0, dash[0], true
+	var current []point
+	if on {
+		current = []point{pts[0]}
+	}
+
+	for i := 0; i+1 < len(pts); i++ {
+		a, b := pts[i], pts[i+1]
+		segLen := math.Hypot(b.X-a.X, b.Y-a.Y)
+		pos := 0.0
+		for segLen-pos > remaining {
+			pos += remaining
+			t := pos / segLen
+			p := point{a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t}
+			if on {
+				out = append(out, subpath{points: append(current, p), dir: point{b.X - a.X, b.Y - a.Y}})
+				current = nil
+			} else {
+				current = []point{p}
+			}
+			on = !on
+			index = (index + 1) % len(dash)
+			remaining = dash[index]
+		}
+		remaining -= segLen - pos
+		if on {
+			current = append(current, b)
+		}
+	}
+	if on && len(current) > 1 {
+		out = append(out, subpath{points: current})
+	}
+	return out
+}
+
+func dashTotal(dash []float64) float64 {
+	total := 0.0
+	for _, d := range dash {
+		if d < 0 {
+			return 0
+		}
+		total += d
+	}
+	return total
+}
+
+// normal renvoie la normale unitaire du segment a → b
+func normal(a, b point) (float64, float64) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return 0, 0
+	}
+	return -dy / l, dx / l
+}
+
+func dedupe(pts []point) []point {
+	out := make([]point, 0, len(pts))
+	for i, p := range pts {
+		if i > 0 && p == out[len(out)-1] {
+			continue
+		}
+		out = append(out, p)
+	}
+	if len(out) > 1 && out[0] == out[len(out)-1] {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func signedArea(p []point) float64 {
+	area := 0.0
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// circlePolygon approche un cercle avec une erreur inférieure à 0,1 pixel
+func circlePolygon(c point, r float64) []point {
+	return ellipsePolygon(c, r, r)
+}
+
+func ellipsePolygon(c point, rx, ry float64) []point {
+	r := math.Max(rx, ry)
+	n := 8
+	if r > 0.1 {
+		n = maxInt(n, int(math.Ceil(math.Pi/math.Acos(1-0.1/r))))
+	}
+	pts := make([]point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = point{c.X + rx*math.Cos(a), c.Y + ry*math.Sin(a)}
+	}
+	return pts
+}
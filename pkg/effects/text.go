@@ -0,0 +1,246 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/nirdeo/goimage/pkg/truetype"
+)
+
+// Dimensions d'un glyphe de la police intégrée
+const (
+	glyphWidth  = 8
+	glyphHeight = 16
+)
+
+// TextAlign aligne les lignes d'un texte entre elles
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+)
+
+// Anchor place un bloc dans l'image; AnchorPoint utilise les coordonnées
+// explicites, les autres ancres se calent sur les bords à une marge donnée
+type Anchor int
+
+const (
+	AnchorPoint Anchor = iota
+	AnchorTopLeft
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCenter
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+var anchorNames = []string{
+	"Coordonnées", "Haut gauche", "Haut", "Haut droite", "Gauche", "Centre",
+	"Droite", "Bas gauche", "Bas", "Bas droite",
+}
+
+func (a Anchor) String() string {
+	if a < 0 || int(a) >= len(anchorNames) {
+		return "Inconnue"
+	}
+	return anchorNames[a]
+}
+
+// place renvoie le coin supérieur gauche d'un bloc w × h ancré dans bounds;
+// pour AnchorPoint, le bloc commence en at
+func (a Anchor) place(bounds image.Rectangle, w, h, margin int, at image.Point) image.Point {
+	if a == AnchorPoint {
+		return bounds.Min.Add(at)
+	}
+	col, row := int(a-AnchorTopLeft)%3, int(a-AnchorTopLeft)/3
+	x := []int{bounds.Min.X + margin, bounds.Min.X + (bounds.Dx()-w)/2, bounds.Max.X - margin - w}[col]
+	y := []int{bounds.Min.Y + margin, bounds.Min.Y + (bounds.Dy()-h)/2, bounds.Max.Y - margin - h}[row]
+	return image.Point{x, y}
+}
+
+// glyph renvoie les lignes du glyphe de r, ou celles de '?' s'il manque
+func glyph(r rune) [glyphHeight]byte {
+	if r >= 0x20 && r <= 0xff {
+		if g := latin1Glyphs[r-0x20]; g != ([glyphHeight]byte{}) || r == ' ' || r == 0xa0 {
+			return g
+		}
+	} else if g, ok := extraGlyphs[r]; ok {
+		return g
+	}
+	return latin1Glyphs['?'-0x20]
+}
+
+// TextEffect écrit un texte, éventuellement sur plusieurs lignes, avec la
+// police bitmap intégrée 8 × 16 (Latin-1, accents français compris) ou une
+// police TrueType anticrénelée
+type TextEffect struct {
+	Text string
+	// X, Y donnent le coin supérieur gauche du bloc quand Anchor vaut AnchorPoint
+	X, Y   int
+	Anchor Anchor
+	// Margin écarte le bloc des bords de l'image pour les autres ancres
+	Margin int
+	Align  TextAlign
+	// Scale agrandit chaque pixel de la police bitmap (1 par défaut)
+	Scale int
+	// Font remplace la police bitmap; FontSize est alors la taille en pixels
+	// par cadratin (un point à 72 ppp)
+	Font     *truetype.Font
+	FontSize float64
+	Color    color.Color
+	// Background remplit un cadre derrière le texte, nil pour aucun
+	Background color.Color
+	// Padding écarte le texte des bords du cadre
+	Padding     int
+	Compositing *Compositing
+}
+
+func (t *TextEffect) Name() string { return "Texte" }
+func (t *TextEffect) Description() string {
+	return "Écrit un texte avec la police bitmap intégrée ou une police TrueType"
+}
+
+// lines découpe le texte en lignes, les tabulations valant quatre espaces
+func (t *TextEffect) lines() [][]rune {
+	text := strings.ReplaceAll(strings.ReplaceAll(t.Text, "\r\n", "\n"), "\t", "    ")
+	var lines [][]rune
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, []rune(line))
+	}
+	return lines
+}
+
+func (t *TextEffect) face() textFace {
+	if t.Font != nil && t.FontSize > 0 {
+		return newTrueTypeFace(t.Font, t.FontSize)
+	}
+	return bitmapFace{maxInt(t.Scale, 1)}
+}
+
+// lineWidth mesure une ligne, crénage compris
+func lineWidth(face textFace, line []rune) float64 {
+	width := 0.0
+	for i, r := range line {
+		if i > 0 {
+			width += face.kern(line[i-1], r)
+		}
+		width += face.advance(r)
+	}
+	return width
+}
+
+// Size renvoie les dimensions du cadre occupé par le texte, marge intérieure comprise
+func (t *TextEffect) Size() (int, int) {
+	face := t.face()
+	width := 0.0
+	lines := t.lines()
+	for _, line := range lines {
+		width = math.Max(width, lineWidth(face, line))
+	}
+	height := float64(len(lines)) * face.lineHeight()
+	return int(math.Ceil(width)) + 2*t.Padding, int(math.Ceil(height)) + 2*t.Padding
+}
+
+func (t *TextEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+
+	face := t.face()
+	w, h := t.Size()
+	origin := t.Anchor.place(bounds, w, h, t.Margin, image.Point{t.X, t.Y})
+	box := image.Rectangle{origin, origin.Add(image.Point{w, h})}.Intersect(bounds)
+
+	if t.Background != nil {
+		paintShape(result, t.Background, t.Compositing, func(dst *image.RGBA, c color.Color) {
+			for y := box.Min.Y; y < box.Max.Y; y++ {
+				for x := box.Min.X; x < box.Max.X; x++ {
+					dst.Set(x, y, c)
+				}
+			}
+		})
+	}
+	if t.Color == nil {
+		return result
+	}
+
+	inner := float64(w - 2*t.Padding)
+	paintShape(result, t.Color, t.Compositing, func(dst *image.RGBA, c color.Color) {
+		for i, line := range t.lines() {
+			x := float64(origin.X + t.Padding)
+			switch t.Align {
+			case AlignCenter:
+				x += math.Round((inner - lineWidth(face, line)) / 2)
+			case AlignRight:
+				x += inner - lineWidth(face, line)
+			}
+			baseline := float64(origin.Y+t.Padding) + float64(i)*face.lineHeight() + face.ascent()
+			for j, r := range line {
+				if j > 0 {
+					x += face.kern(line[j-1], r)
+				}
+				face.draw(dst, r, x, baseline, c)
+				x += face.advance(r)
+			}
+		}
+	})
+	return result
+}
+
+// textFace fournit les mesures et le dessin des glyphes d'une police, en pixels
+type textFace interface {
+	ascent() float64
+	lineHeight() float64
+	advance(r rune) float64
+	kern(left, right rune) float64
+	// draw dessine le glyphe avec son origine en (x, baseline)
+	draw(dst *image.RGBA, r rune, x, baseline float64, c color.Color)
+}
+
+// bitmapFace est la police intégrée agrandie d'un facteur entier
+type bitmapFace struct {
+	scale int
+}
+
+// La ligne de base passe sous la 13e ligne du glyphe
+const glyphAscent = 13
+
+func (f bitmapFace) ascent() float64               { return float64(glyphAscent * f.scale) }
+func (f bitmapFace) lineHeight() float64           { return float64(glyphHeight * f.scale) }
+func (f bitmapFace) advance(rune) float64          { return float64(glyphWidth * f.scale) }
+func (f bitmapFace) kern(left, right rune) float64 { return 0 }
+
+func (f bitmapFace) draw(dst *image.RGBA, r rune, x, baseline float64, c color.Color) {
+	drawGlyph(dst, glyph(r), int(x), int(baseline)-glyphAscent*f.scale, f.scale, c)
+}
+
+// drawGlyph dessine un glyphe en agrandissant chaque pixel en carré scale × scale
+func drawGlyph(img *image.RGBA, g [glyphHeight]byte, x0, y0, scale int, c color.Color) {
+	bounds := img.Bounds()
+	for row, bits := range g {
+		for col := 0; col < glyphWidth; col++ {
+			if bits&(0x80>>col) == 0 {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					p := image.Point{x0 + col*scale + dx, y0 + row*scale + dy}
+					if p.In(bounds) {
+						img.Set(p.X, p.Y, c)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/nirdeo/goimage/pkg/truetype"
+)
+
+// trueTypeFace met une police TrueType à l'échelle et garde en cache les
+// contours aplatis de chaque caractère
+type trueTypeFace struct {
+	font  *truetype.Font
+	scale float64
+	cache map[rune][]subpath
+}
+
+func newTrueTypeFace(font *truetype.Font, size float64) *trueTypeFace {
+	return &trueTypeFace{
+		font:  font,
+		scale: size / float64(font.UnitsPerEm()),
+		cache: map[rune][]subpath{},
+	}
+}
+
+func (f *trueTypeFace) ascent() float64 {
+	ascent, _, _ := f.font.Metrics()
+	return math.Round(float64(ascent) * f.scale)
+}
+
+func (f *trueTypeFace) lineHeight() float64 {
+	ascent, descent, gap := f.font.Metrics()
+	return math.Ceil(float64(ascent-descent+gap) * f.scale)
+}
+
+func (f *trueTypeFace) advance(r rune) float64 {
+	return float64(f.font.Advance(f.font.Index(r))) * f.scale
+}
+
+func (f *trueTypeFace) kern(left, right rune) float64 {
+	return float64(f.font.Kern(f.font.Index(left), f.font.Index(right))) * f.scale
+}
+
+// outline renvoie les contours du glyphe en pixels, origine en (0, 0) et
+// axe Y vers le bas
+func (f *trueTypeFace) outline(r rune) []subpath {
+	if paths, ok := f.cache[r]; ok {
+		return paths
+	}
+	contours, err := f.font.Glyph(f.font.Index(r))
+	if err != nil {
+		contours = nil
+	}
+	path := &Path{}
+	for _, contour := range contours {
+		addContour(path, contour, f.scale)
+	}
+	f.cache[r] = path.subpaths()
+	return f.cache[r]
+}
+
+func (f *trueTypeFace) draw(dst *image.RGBA, r rune, x, baseline float64, c color.Color) {
+	paths := f.outline(r)
+	if len(paths) == 0 {
+		return
+	}
+	// Contours déplacés à la position du glyphe, rastérisés sur leur seul
+	// rectangle englobant
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	polys := make([][]point, len(paths))
+	for i, sp := range paths {
+		poly := make([]point, len(sp.points))
+		for j, p := range sp.points {
+			poly[j] = point{p.X + x, p.Y + baseline}
+			minX, maxX = math.Min(minX, poly[j].X), math.Max(maxX, poly[j].X)
+			minY, maxY = math.Min(minY, poly[j].Y), math.Max(maxY, poly[j].Y)
+		}
+		polys[i] = poly
+	}
+	area := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1).Intersect(dst.Bounds())
+	if area.Empty() {
+		return
+	}
+	rasterizePolygons(polys, area, NonZero, true, func(px, py int, coverage float64) {
+		blendPixel(dst, px, py, c, coverage)
+	})
+}
+
+// addContour convertit un contour TrueType (B-spline quadratique) en
+// segments et courbes; l'axe Y est retourné
+func addContour(path *Path, contour []truetype.Point, scale float64) {
+	n := len(contour)
+	if n == 0 {
+		return
+	}
+	pt := func(p truetype.Point) (float64, float64) { return p.X * scale, -p.Y * scale }
+	mid := func(a, b truetype.Point) truetype.Point {
+		return truetype.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2, OnCurve: true}
+	}
+
+	// Départ sur un point de la courbe, implicite si tous sont hors courbe:
+	// les n-1 autres points suivent, ou les n points dans le second cas
+	first, count := -1, n-1
+	for i, p := range contour {
+		if p.OnCurve {
+			first = i
+			break
+		}
+	}
+	var start truetype.Point
+	if first < 0 {
+		start, first, count = mid(contour[0], contour[1%n]), 0, n
+	} else {
+		start = contour[first]
+	}
+	path.MoveTo(pt(start))
+
+	var control *truetype.Point
+	for k := 1; k <= count; k++ {
+		p := contour[(first+k)%n]
+		if p.OnCurve {
+			if control != nil {
+				cx, cy := pt(*control)
+				x, y := pt(p)
+				path.QuadTo(cx, cy, x, y)
+				control = nil
+			} else {
+				path.LineTo(pt(p))
+			}
+			continue
+		}
+		if control != nil {
+			// Deux points hors courbe: point sur la courbe implicite entre eux
+			cx, cy := pt(*control)
+			x, y := pt(mid(*control, p))
+			path.QuadTo(cx, cy, x, y)
+		}
+		c := p
+		control = &c
+	}
+	if control != nil {
+		cx, cy := pt(*control)
+		x, y := pt(start)
+		path.QuadTo(cx, cy, x, y)
+	}
+	path.Close()
+}
@@ -0,0 +1,177 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/nirdeo/goimage/pkg/truetype"
+)
+
+// WatermarkEffect appose un motif (logo ou texte rendu par TextMark) à une
+// ancre de l'image, ou le répète en mosaïque décalée
+type WatermarkEffect struct {
+	Mark image.Image
+	// X, Y placent le motif quand Anchor vaut AnchorPoint
+	X, Y   int
+	Anchor Anchor
+	Margin int
+	// Scale donne la largeur du motif en fraction de celle de l'image
+	// (0.2 = 20 %); 0 conserve la taille d'origine
+	Scale   float64
+	Opacity float64
+	// Rotation en degrés, sens antihoraire
+	Rotation float64
+	// Tiled répète le motif sur toute l'image, une rangée sur deux décalée
+	// d'une demi-période; Spacing sépare les motifs, en pixels
+	Tiled   bool
+	Spacing int
+}
+
+func (w *WatermarkEffect) Name() string { return "Filigrane" }
+func (w *WatermarkEffect) Description() string {
+	return "Appose un logo ou un texte semi-transparent, à une ancre ou en mosaïque"
+}
+
+func (w *WatermarkEffect) Apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	result := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			result.Set(x, y, img.At(x, y))
+		}
+	}
+	if w.Mark == nil || w.Mark.Bounds().Empty() {
+		return result
+	}
+
+	factor := 1.0
+	if w.Scale > 0 {
+		factor = w.Scale * float64(bounds.Dx()) / float64(w.Mark.Bounds().Dx())
+	}
+	mark := transformMark(w.Mark, factor, w.Rotation)
+	mb := mark.Bounds()
+	comp := &Compositing{Opacity: w.Opacity}
+
+	stamp := func(at image.Point) {
+		for y := 0; y < mb.Dy(); y++ {
+			for x := 0; x < mb.Dx(); x++ {
+				c := mark.RGBAAt(x, y)
+				if c.A == 0 {
+					continue
+				}
+				src := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+				comp.compose(result, at.X+x, at.Y+y, src, 1)
+			}
+		}
+	}
+
+	if !w.Tiled {
+		stamp(w.Anchor.place(bounds, mb.Dx(), mb.Dy(), w.Margin, image.Point{w.X, w.Y}))
+		return result
+	}
+	stepX, stepY := mb.Dx()+w.Spacing, mb.Dy()+w.Spacing
+	if stepX <= 0 || stepY <= 0 {
+		return result
+	}
+	for row, y := 0, bounds.Min.Y-stepY/2; y < bounds.Max.Y; row, y = row+1, y+stepY {
+		x := bounds.Min.X - stepX/2
+		if row%2 == 1 {
+			x += stepX / 2
+		}
+		for ; x < bounds.Max.X; x += stepX {
+			stamp(image.Point{x, y})
+		}
+	}
+	return result
+}
+
+// TextMark rend un texte sur fond transparent pour servir de filigrane;
+// font peut être nil pour la police bitmap intégrée agrandie de scale
+func TextMark(text string, font *truetype.Font, size float64, scale int, c color.Color) image.Image {
+	t := &TextEffect{Text: text, Font: font, FontSize: size, Scale: scale, Color: c}
+	w, h := t.Size()
+	return t.Apply(image.NewRGBA(image.Rect(0, 0, w, h)))
+}
+
+// transformMark agrandit le motif du facteur donné puis le fait tourner de
+// angle degrés (sens antihoraire) autour de son centre. L'image renvoyée,
+// prémultipliée, englobe le motif tourné; les réductions sont
+// suréchantillonnées pour éviter le crénelage
+func transformMark(mark image.Image, factor, angle float64) *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, mark.Bounds().Dx(), mark.Bounds().Dy()))
+	mb := mark.Bounds()
+	for y := 0; y < src.Rect.Dy(); y++ {
+		for x := 0; x < src.Rect.Dx(); x++ {
+			src.Set(x, y, mark.At(mb.Min.X+x, mb.Min.Y+y))
+		}
+	}
+	if factor == 1 && angle == 0 {
+		return src
+	}
+
+	w, h := float64(src.Rect.Dx())*factor, float64(src.Rect.Dy())*factor
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	outW := int(math.Ceil(math.Abs(w*cos) + math.Abs(h*sin)))
+	outH := int(math.Ceil(math.Abs(w*sin) + math.Abs(h*cos)))
+	out := image.NewRGBA(image.Rect(0, 0, maxInt(outW, 1), maxInt(outH, 1)))
+
+	samples := 1
+	if factor < 1 {
+		samples = minInt(4, int(math.Ceil(1/factor)))
+	}
+	n := float64(samples * samples)
+	for v := 0; v < outH; v++ {
+		for u := 0; u < outW; u++ {
+			var acc [4]float64
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					// Position relative au centre, puis rotation inverse
+					dx := float64(u) + (float64(sx)+0.5)/float64(samples) - float64(outW)/2
+					dy := float64(v) + (float64(sy)+0.5)/float64(samples) - float64(outH)/2
+					x := dx*cos - dy*sin
+					y := dx*sin + dy*cos
+					c := bilinear(src, (x+w/2)/factor-0.5, (y+h/2)/factor-0.5)
+					for i := range acc {
+						acc[i] += c[i]
+					}
+				}
+			}
+			out.SetRGBA(u, v, color.RGBA{
+				uint8(math.Round(acc[0] / n)), uint8(math.Round(acc[1] / n)),
+				uint8(math.Round(acc[2] / n)), uint8(math.Round(acc[3] / n)),
+			})
+		}
+	}
+	return out
+}
+
+// bilinear interpole les composantes prémultipliées autour de (x, y),
+// l'extérieur de l'image étant transparent
+func bilinear(img *image.RGBA, x, y float64) [4]float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	var out [4]float64
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 2; i++ {
+			p := image.Point{int(x0) + i, int(y0) + j}
+			if !p.In(img.Rect) {
+				continue
+			}
+			wx, wy := 1-fx, 1-fy
+			if i == 1 {
+				wx = fx
+			}
+			if j == 1 {
+				wy = fy
+			}
+			c := img.RGBAAt(p.X, p.Y)
+			k := wx * wy
+			out[0] += float64(c.R) * k
+			out[1] += float64(c.G) * k
+			out[2] += float64(c.B) * k
+			out[3] += float64(c.A) * k
+		}
+	}
+	return out
+}
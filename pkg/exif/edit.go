@@ -0,0 +1,96 @@
+package exif
+
+import "encoding/binary"
+
+// SetOrientation renvoie une copie du bloc EXIF avec le tag d'orientation
+// remplacé; utile une fois l'image redressée pour ne pas la tourner deux fois
+func SetOrientation(data []byte, orientation int) ([]byte, error) {
+	out := append([]byte(nil), data...)
+	r, ifd0, err := openIFD0(out)
+	if err != nil {
+		return nil, err
+	}
+	if e := findEntry(r, ifd0, tagOrientation); e >= 0 && r.bo.Uint16(out[e+2:]) == typeShort {
+		r.bo.PutUint16(out[e+8:], uint16(orientation))
+	}
+	return out, nil
+}
+
+// StripGPS renvoie une copie du bloc EXIF sans la sous-IFD GPS: l'entrée est
+// retirée de l'IFD0 et les données de localisation sont mises à zéro
+func StripGPS(data []byte) ([]byte, error) {
+	out := append([]byte(nil), data...)
+	r, ifd0, err := openIFD0(out)
+	if err != nil {
+		return nil, err
+	}
+	e := findEntry(r, ifd0, tagGPSIFD)
+	if e < 0 {
+		return out, nil
+	}
+
+	// Effacement des entrées GPS et des valeurs qu'elles référencent
+	gpsOff := r.bo.Uint32(out[e+8:])
+	if int64(gpsOff)+2 <= int64(len(out)) {
+		n := int(r.bo.Uint16(out[gpsOff:]))
+		for i := 0; i < n; i++ {
+			pos := int(gpsOff) + 2 + i*12
+			if pos+12 > len(out) {
+				break
+			}
+			size := uint64(typeSizes[r.bo.Uint16(out[pos+2:])]) * uint64(r.bo.Uint32(out[pos+4:]))
+			if size > 4 {
+				voff := uint64(r.bo.Uint32(out[pos+8:]))
+				if voff+size <= uint64(len(out)) {
+					clear(out[voff : voff+size])
+				}
+			}
+			clear(out[pos : pos+12])
+		}
+	}
+
+	// Retrait de l'entrée: les suivantes et le lien vers l'IFD suivante
+	// remontent de 12 octets, les offsets absolus restent valides
+	n := int(r.bo.Uint16(out[ifd0:]))
+	end := ifd0 + 2 + n*12 + 4
+	copy(out[e:], out[e+12:end])
+	clear(out[end-12 : end])
+	r.bo.PutUint16(out[ifd0:], uint16(n-1))
+	return out, nil
+}
+
+func openIFD0(data []byte) (*reader, int, error) {
+	if len(data) < 8 {
+		return nil, 0, errFormat
+	}
+	r := &reader{data: data}
+	switch string(data[:4]) {
+	case "II*\x00":
+		r.bo = binary.LittleEndian
+	case "MM\x00*":
+		r.bo = binary.BigEndian
+	default:
+		return nil, 0, errFormat
+	}
+	off := int64(r.bo.Uint32(data[4:8]))
+	if off+2 > int64(len(data)) {
+		return nil, 0, errFormat
+	}
+	n := int64(r.bo.Uint16(data[off:]))
+	if off+2+n*12+4 > int64(len(data)) {
+		return nil, 0, errFormat
+	}
+	return r, int(off), nil
+}
+
+// findEntry renvoie la position de l'entrée tag dans l'IFD, ou -1
+func findEntry(r *reader, ifd int, tag uint16) int {
+	n := int(r.bo.Uint16(r.data[ifd:]))
+	for i := 0; i < n; i++ {
+		pos := ifd + 2 + i*12
+		if r.bo.Uint16(r.data[pos:]) == tag {
+			return pos
+		}
+	}
+	return -1
+}
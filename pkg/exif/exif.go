@@ -0,0 +1,290 @@
+// Package exif lit les métadonnées EXIF (structure TIFF) des photos:
+// appareil, date, exposition, GPS et orientation.
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Tags EXIF exploités
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagArtist           = 0x013b
+	tagCopyright        = 0x8298
+	tagExposureTime     = 0x829a
+	tagFNumber          = 0x829d
+	tagExifIFD          = 0x8769
+	tagISO              = 0x8827
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagExposureBias     = 0x9204
+	tagFlash            = 0x9209
+	tagFocalLength      = 0x920a
+	tagLensModel        = 0xa434
+
+	gpsLatitudeRef  = 0x01
+	gpsLatitude     = 0x02
+	gpsLongitudeRef = 0x03
+	gpsLongitude    = 0x04
+	gpsAltitudeRef  = 0x05
+	gpsAltitude     = 0x06
+)
+
+// Types de champs
+const (
+	typeByte      = 1
+	typeASCII     = 2
+	typeShort     = 3
+	typeLong      = 4
+	typeRational  = 5
+	typeUndefined = 7
+	typeSLong     = 9
+	typeSRational = 10
+)
+
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+var errFormat = errors.New("exif: structure TIFF invalide")
+
+// Rational est une fraction EXIF
+type Rational struct {
+	Num, Den int64
+}
+
+// Float renvoie la valeur décimale (0 si le dénominateur est nul)
+func (r Rational) Float() float64 {
+	if r.Den == 0 {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// String affiche une durée d'exposition sous la forme usuelle (1/125 s)
+func (r Rational) String() string {
+	if r.Den == 0 {
+		return "?"
+	}
+	if r.Num > 0 && r.Num < r.Den {
+		return fmt.Sprintf("1/%d", int64(math.Round(float64(r.Den)/float64(r.Num))))
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", r.Float()), "0"), ".")
+}
+
+// GPS regroupe la position en degrés décimaux et l'altitude en mètres
+type GPS struct {
+	Latitude, Longitude float64
+	Altitude            float64
+	HasAltitude         bool
+}
+
+// Exif contient les champs reconnus d'un bloc EXIF
+type Exif struct {
+	Make, Model  string
+	LensModel    string
+	Software     string
+	Artist       string
+	Copyright    string
+	DateTime     time.Time
+	ExposureTime Rational
+	FNumber      float64
+	ISO          int
+	FocalLength  float64
+	ExposureBias float64
+	Flash        bool
+	HasFlash     bool
+	// Orientation vaut 1 à 8 (1 = normale)
+	Orientation int
+	GPS         *GPS
+}
+
+// Camera renvoie la marque et le modèle sans répétition
+func (e *Exif) Camera() string {
+	if e.Make != "" && strings.HasPrefix(strings.ToLower(e.Model), strings.ToLower(e.Make)) {
+		return e.Model
+	}
+	return strings.TrimSpace(e.Make + " " + e.Model)
+}
+
+type field struct {
+	typ   uint16
+	count uint32
+	raw   []byte
+}
+
+type reader struct {
+	data []byte
+	bo   binary.ByteOrder
+}
+
+// Parse décode un bloc EXIF au format TIFF (sans le préfixe "Exif\0\0")
+func Parse(data []byte) (*Exif, error) {
+	r, off, err := openIFD0(data)
+	if err != nil {
+		return nil, err
+	}
+	ifd0, err := r.readIFD(uint32(off))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exif{
+		Make:        r.str(ifd0, tagMake),
+		Model:       r.str(ifd0, tagModel),
+		Software:    r.str(ifd0, tagSoftware),
+		Artist:      r.str(ifd0, tagArtist),
+		Copyright:   r.str(ifd0, tagCopyright),
+		Orientation: int(r.uint(ifd0, tagOrientation, 1)),
+		DateTime:    parseTime(r.str(ifd0, tagDateTime)),
+	}
+	if e.Orientation < 1 || e.Orientation > 8 {
+		e.Orientation = 1
+	}
+
+	// Sous-IFD EXIF: paramètres de prise de vue
+	if off := r.uint(ifd0, tagExifIFD, 0); off != 0 {
+		if sub, err := r.readIFD(uint32(off)); err == nil {
+			if t := parseTime(r.str(sub, tagDateTimeOriginal)); !t.IsZero() {
+				e.DateTime = t
+			}
+			e.ExposureTime = r.rat(sub, tagExposureTime, 0)
+			e.FNumber = r.rat(sub, tagFNumber, 0).Float()
+			e.ISO = int(r.uint(sub, tagISO, 0))
+			e.FocalLength = r.rat(sub, tagFocalLength, 0).Float()
+			e.ExposureBias = r.rat(sub, tagExposureBias, 0).Float()
+			e.LensModel = r.str(sub, tagLensModel)
+			if _, ok := sub[tagFlash]; ok {
+				e.HasFlash = true
+				e.Flash = r.uint(sub, tagFlash, 0)&1 == 1
+			}
+		}
+	}
+
+	// Sous-IFD GPS
+	if off := r.uint(ifd0, tagGPSIFD, 0); off != 0 {
+		if gps, err := r.readIFD(uint32(off)); err == nil {
+			e.GPS = r.gps(gps)
+		}
+	}
+	return e, nil
+}
+
+func (r *reader) readIFD(off uint32) (map[uint16]field, error) {
+	if int64(off)+2 > int64(len(r.data)) {
+		return nil, errFormat
+	}
+	n := int(r.bo.Uint16(r.data[off:]))
+	if int64(off)+2+int64(n)*12 > int64(len(r.data)) {
+		return nil, errFormat
+	}
+
+	fields := make(map[uint16]field, n)
+	for i := 0; i < n; i++ {
+		e := r.data[int(off)+2+i*12:]
+		tag := r.bo.Uint16(e[0:2])
+		typ := r.bo.Uint16(e[2:4])
+		count := r.bo.Uint32(e[4:8])
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := uint64(size) * uint64(count)
+		var raw []byte
+		if total <= 4 {
+			raw = e[8 : 8+total]
+		} else {
+			voff := uint64(r.bo.Uint32(e[8:12]))
+			if voff+total > uint64(len(r.data)) {
+				continue
+			}
+			raw = r.data[voff : voff+total]
+		}
+		fields[tag] = field{typ, count, raw}
+	}
+	return fields, nil
+}
+
+func (r *reader) str(ifd map[uint16]field, tag uint16) string {
+	f, ok := ifd[tag]
+	if !ok || (f.typ != typeASCII && f.typ != typeUndefined) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(f.raw), "\x00"))
+}
+
+func (r *reader) uint(ifd map[uint16]field, tag uint16, def uint32) uint32 {
+	f, ok := ifd[tag]
+	if !ok || f.count == 0 {
+		return def
+	}
+	switch f.typ {
+	case typeByte, typeUndefined:
+		return uint32(f.raw[0])
+	case typeShort:
+		return uint32(r.bo.Uint16(f.raw))
+	case typeLong, typeSLong:
+		return r.bo.Uint32(f.raw)
+	}
+	return def
+}
+
+func (r *reader) rat(ifd map[uint16]field, tag uint16, i int) Rational {
+	f, ok := ifd[tag]
+	if !ok || uint32(i) >= f.count || len(f.raw) < 8*(i+1) {
+		return Rational{}
+	}
+	// Un tag stocké dans un autre type (SHORT, LONG...) est ignoré
+	if f.typ != typeRational && f.typ != typeSRational {
+		return Rational{}
+	}
+	raw := f.raw[8*i:]
+	switch f.typ {
+	case typeRational:
+		return Rational{int64(r.bo.Uint32(raw)), int64(r.bo.Uint32(raw[4:]))}
+	case typeSRational:
+		return Rational{int64(int32(r.bo.Uint32(raw))), int64(int32(r.bo.Uint32(raw[4:])))}
+	}
+	return Rational{}
+}
+
+func (r *reader) gps(ifd map[uint16]field) *GPS {
+	if _, ok := ifd[gpsLatitude]; !ok {
+		return nil
+	}
+	// Degrés, minutes, secondes
+	dms := func(tag uint16) float64 {
+		return r.rat(ifd, tag, 0).Float() + r.rat(ifd, tag, 1).Float()/60 + r.rat(ifd, tag, 2).Float()/3600
+	}
+	g := &GPS{Latitude: dms(gpsLatitude), Longitude: dms(gpsLongitude)}
+	if r.str(ifd, gpsLatitudeRef) == "S" {
+		g.Latitude = -g.Latitude
+	}
+	if r.str(ifd, gpsLongitudeRef) == "W" {
+		g.Longitude = -g.Longitude
+	}
+	if _, ok := ifd[gpsAltitude]; ok {
+		g.HasAltitude = true
+		g.Altitude = r.rat(ifd, gpsAltitude, 0).Float()
+		if r.uint(ifd, gpsAltitudeRef, 0) == 1 {
+			g.Altitude = -g.Altitude
+		}
+	}
+	return g
+}
+
+// parseTime lit le format EXIF "2006:01:02 15:04:05"
+func parseTime(s string) time.Time {
+	t, err := time.Parse("2006:01:02 15:04:05", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
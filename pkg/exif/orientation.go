@@ -0,0 +1,61 @@
+package exif
+
+import "image"
+
+var orientationNames = map[int]string{
+	1: "Normale",
+	2: "Miroir horizontal",
+	3: "Rotation 180°",
+	4: "Miroir vertical",
+	5: "Transposée (miroir + rotation 90° antihoraire)",
+	6: "Rotation 90° horaire",
+	7: "Transverse (miroir + rotation 90° horaire)",
+	8: "Rotation 90° antihoraire",
+}
+
+// OrientationName décrit la transformation à appliquer pour l'affichage
+func OrientationName(o int) string {
+	if name, ok := orientationNames[o]; ok {
+		return name
+	}
+	return "Inconnue"
+}
+
+// ApplyOrientation redresse l'image selon la valeur EXIF (1 à 8)
+func ApplyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	result := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			result.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return result
+}
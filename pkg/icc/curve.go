@@ -0,0 +1,115 @@
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Curve convertit une composante encodée (0 à 1) en valeur linéaire
+type Curve interface {
+	Eval(x float64) float64
+}
+
+// gammaCurve est une simple loi de puissance
+type gammaCurve float64
+
+func (g gammaCurve) Eval(x float64) float64 {
+	return math.Pow(x, float64(g))
+}
+
+// tableCurve est échantillonnée régulièrement et interpolée linéairement
+type tableCurve []float64
+
+func (t tableCurve) Eval(x float64) float64 {
+	pos := clamp01(x) * float64(len(t)-1)
+	i := int(pos)
+	if i >= len(t)-1 {
+		return t[len(t)-1]
+	}
+	f := pos - float64(i)
+	return t[i]*(1-f) + t[i+1]*f
+}
+
+// parametricCurve suit les fonctions 0 à 4 du type 'para'
+type parametricCurve struct {
+	kind                int
+	g, a, b, c, d, e, f float64
+}
+
+func (p parametricCurve) Eval(x float64) float64 {
+	var y float64
+	switch p.kind {
+	case 0:
+		y = math.Pow(x, p.g)
+	case 1:
+		if x >= -p.b/p.a {
+			y = math.Pow(p.a*x+p.b, p.g)
+		}
+	case 2:
+		y = p.c
+		if x >= -p.b/p.a {
+			y = math.Pow(p.a*x+p.b, p.g) + p.c
+		}
+	case 3:
+		y = p.c * x
+		if x >= p.d {
+			y = math.Pow(p.a*x+p.b, p.g)
+		}
+	case 4:
+		y = p.c*x + p.f
+		if x >= p.d {
+			y = math.Pow(p.a*x+p.b, p.g) + p.e
+		}
+	}
+	return clamp01(y)
+}
+
+// Nombre de paramètres des fonctions 'para'
+var paramCounts = []int{1, 3, 4, 5, 7}
+
+func parseCurve(b []byte) (Curve, error) {
+	be := binary.BigEndian
+	if len(b) < 12 {
+		return nil, errFormat
+	}
+	switch string(b[:4]) {
+	case "curv":
+		n := int(be.Uint32(b[8:]))
+		if 12+2*n > len(b) {
+			return nil, errFormat
+		}
+		switch n {
+		case 0:
+			return gammaCurve(1), nil
+		case 1:
+			return gammaCurve(float64(be.Uint16(b[12:])) / 256), nil
+		}
+		t := make(tableCurve, n)
+		for i := range t {
+			t[i] = float64(be.Uint16(b[12+2*i:])) / 65535
+		}
+		return t, nil
+	case "para":
+		kind := int(be.Uint16(b[8:]))
+		if kind >= len(paramCounts) || 12+4*paramCounts[kind] > len(b) {
+			return nil, errors.New("icc: courbe paramétrique non supportée")
+		}
+		var v [7]float64
+		for i := 0; i < paramCounts[kind]; i++ {
+			v[i] = s15Fixed16(b[12+4*i:])
+		}
+		return parametricCurve{kind, v[0], v[1], v[2], v[3], v[4], v[5], v[6]}, nil
+	}
+	return nil, errors.New("icc: type de courbe non supporté")
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,146 @@
+// Package icc lit les profils de couleurs ICC de type matrice/TRC (RVB) et
+// convertit les images vers sRGB.
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+const headerSize = 128
+
+var errFormat = errors.New("icc: profil invalide")
+
+// Profile est un profil RVB défini par trois colorants et trois courbes
+type Profile struct {
+	// Class est la classe du profil ("mntr", "scnr", "spac"...)
+	Class string
+	// ColorSpace est l'espace des données ("RGB ", "GRAY", "CMYK"...)
+	ColorSpace  string
+	Version     string
+	Description string
+	// Matrix convertit le RVB linéaire en XYZ (D50); colonnes = colorants
+	Matrix [3][3]float64
+	Curves [3]Curve
+}
+
+// Parse décode un profil ICC
+func Parse(data []byte) (*Profile, error) {
+	if len(data) < headerSize+4 || string(data[36:40]) != "acsp" {
+		return nil, errFormat
+	}
+	be := binary.BigEndian
+	p := &Profile{
+		Class:      string(data[12:16]),
+		ColorSpace: string(data[16:20]),
+		Version:    fmt.Sprintf("%d.%d", data[8], data[9]>>4),
+	}
+
+	tags := map[string][]byte{}
+	count := int(be.Uint32(data[headerSize:]))
+	if headerSize+4+count*12 > len(data) {
+		return nil, errFormat
+	}
+	for i := 0; i < count; i++ {
+		e := data[headerSize+4+i*12:]
+		off, size := uint64(be.Uint32(e[4:])), uint64(be.Uint32(e[8:]))
+		if off+size > uint64(len(data)) || size < 8 {
+			continue
+		}
+		tags[string(e[:4])] = data[off : off+size]
+	}
+
+	if d, ok := tags["desc"]; ok {
+		p.Description = parseText(d)
+	}
+	if p.ColorSpace != "RGB " {
+		return p, fmt.Errorf("icc: espace %q non supporté", strings.TrimSpace(p.ColorSpace))
+	}
+
+	for i, name := range []string{"r", "g", "b"} {
+		xyz, ok := tags[name+"XYZ"]
+		if !ok || len(xyz) < 20 || string(xyz[:4]) != "XYZ " {
+			return p, errors.New("icc: profil sans colorants (LUT non supportées)")
+		}
+		for j := 0; j < 3; j++ {
+			p.Matrix[j][i] = s15Fixed16(xyz[8+4*j:])
+		}
+		trc, ok := tags[name+"TRC"]
+		if !ok {
+			return p, errors.New("icc: courbe de réponse manquante")
+		}
+		curve, err := parseCurve(trc)
+		if err != nil {
+			return p, err
+		}
+		p.Curves[i] = curve
+	}
+	return p, nil
+}
+
+// IsSRGB indique si le profil équivaut à sRGB (colorants et courbes)
+func (p *Profile) IsSRGB() bool {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if abs(p.Matrix[i][j]-srgbMatrix[i][j]) > 0.01 {
+				return false
+			}
+		}
+	}
+	for _, c := range p.Curves {
+		if c == nil {
+			return false
+		}
+		for _, x := range []float64{0.05, 0.2, 0.5, 0.8} {
+			if abs(c.Eval(x)-srgbDecode(x)) > 0.01 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// parseText lit une description 'desc' (ICC v2) ou 'mluc' (ICC v4)
+func parseText(b []byte) string {
+	be := binary.BigEndian
+	switch string(b[:4]) {
+	case "desc":
+		if len(b) < 12 {
+			return ""
+		}
+		n := int(be.Uint32(b[8:]))
+		if 12+n > len(b) {
+			return ""
+		}
+		return strings.TrimRight(string(b[12:12+n]), "\x00")
+	case "mluc":
+		if len(b) < 28 || be.Uint32(b[8:]) == 0 {
+			return ""
+		}
+		// Premier enregistrement, en UTF-16 gros-boutiste
+		n, off := int(be.Uint32(b[20:])), int(be.Uint32(b[24:]))
+		if off+n > len(b) {
+			return ""
+		}
+		u := make([]uint16, n/2)
+		for i := range u {
+			u[i] = be.Uint16(b[off+2*i:])
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	}
+	return ""
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
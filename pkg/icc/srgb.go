@@ -0,0 +1,128 @@
+package icc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// Colorants sRGB adaptés à D50 (Bradford), tels que publiés dans le profil
+// sRGB IEC61966-2.1
+var srgbMatrix = [3][3]float64{
+	{0.4360747, 0.3850649, 0.1430804},
+	{0.2225045, 0.7168786, 0.0606169},
+	{0.0139322, 0.0971045, 0.7141733},
+}
+
+// srgbDecode applique la courbe sRGB inverse (valeur encodée vers linéaire)
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// srgbEncode applique la courbe sRGB (valeur linéaire vers encodée)
+func srgbEncode(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// SRGB renvoie un profil ICC v2 sRGB à embarquer dans les fichiers exportés
+func SRGB() []byte {
+	be := binary.BigEndian
+	type tag struct {
+		sig  string
+		data []byte
+	}
+
+	xyz := func(x, y, z float64) []byte {
+		b := make([]byte, 20)
+		copy(b, "XYZ ")
+		for i, v := range []float64{x, y, z} {
+			be.PutUint32(b[8+4*i:], uint32(int32(math.Round(v*65536))))
+		}
+		return b
+	}
+	text := func(typ, s string) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(typ)
+		buf.Write(make([]byte, 4))
+		if typ == "desc" {
+			// Description ASCII, puis champs Unicode et ScriptCode vides
+			binary.Write(&buf, be, uint32(len(s)+1))
+			buf.WriteString(s)
+			buf.WriteByte(0)
+			buf.Write(make([]byte, 4+4+2+1+67))
+		} else {
+			buf.WriteString(s)
+			buf.WriteByte(0)
+		}
+		return buf.Bytes()
+	}
+
+	const n = 1024
+	trc := make([]byte, 12+2*n)
+	copy(trc, "curv")
+	be.PutUint32(trc[8:], n)
+	for i := 0; i < n; i++ {
+		v := srgbDecode(float64(i) / (n - 1))
+		be.PutUint16(trc[12+2*i:], uint16(math.Round(v*65535)))
+	}
+
+	m := srgbMatrix
+	tags := []tag{
+		{"desc", text("desc", "sRGB IEC61966-2.1")},
+		{"cprt", text("text", "Public Domain")},
+		{"wtpt", xyz(0.9504559, 1, 1.0890578)},
+		{"rXYZ", xyz(m[0][0], m[1][0], m[2][0])},
+		{"gXYZ", xyz(m[0][1], m[1][1], m[2][1])},
+		{"bXYZ", xyz(m[0][2], m[1][2], m[2][2])},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	// Table des tags puis données alignées sur 4 octets; les trois courbes
+	// partagent les mêmes données
+	offset := headerSize + 4 + 12*len(tags)
+	var table, body bytes.Buffer
+	binary.Write(&table, be, uint32(len(tags)))
+	var trcOffset int
+	for _, t := range tags {
+		off := offset + body.Len()
+		if t.sig == "gTRC" || t.sig == "bTRC" {
+			off = trcOffset
+		} else {
+			if t.sig == "rTRC" {
+				trcOffset = off
+			}
+			body.Write(t.data)
+			for body.Len()%4 != 0 {
+				body.WriteByte(0)
+			}
+		}
+		table.WriteString(t.sig)
+		binary.Write(&table, be, uint32(off))
+		binary.Write(&table, be, uint32(len(t.data)))
+	}
+
+	header := make([]byte, headerSize)
+	size := headerSize + table.Len() + body.Len()
+	be.PutUint32(header[0:], uint32(size))
+	be.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntr")
+	copy(header[16:], "RGB ")
+	copy(header[20:], "XYZ ")
+	for i, v := range []uint16{2024, 1, 1} {
+		be.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	// Illuminant de la PCS: D50
+	copy(header[68:], xyz(0.9642, 1, 0.8249)[8:])
+
+	out := append(header, table.Bytes()...)
+	return append(out, body.Bytes()...)
+}
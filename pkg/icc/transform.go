@@ -0,0 +1,98 @@
+package icc
+
+import (
+	"image"
+	"image/color"
+)
+
+// Taille des tables de conversion (précision de 12 bits)
+const lutSize = 4096
+
+// ToSRGB convertit les pixels décrits par le profil vers sRGB; la
+// transparence est conservée et la précision 16 bits aussi
+func ToSRGB(img image.Image, p *Profile) image.Image {
+	// Matrice combinée: RVB linéaire source -> XYZ D50 -> RVB linéaire sRGB
+	m := mul(invert(srgbMatrix), p.Matrix)
+
+	var in [3][lutSize]float64
+	for c := 0; c < 3; c++ {
+		for i := range in[c] {
+			in[c][i] = p.Curves[c].Eval(float64(i) / (lutSize - 1))
+		}
+	}
+	var out [lutSize]uint16
+	for i := range out {
+		out[i] = uint16(srgbEncode(float64(i)/(lutSize-1))*65535 + 0.5)
+	}
+	encode := func(v float64) uint16 {
+		return out[int(clamp01(v)*(lutSize-1)+0.5)]
+	}
+
+	bounds := img.Bounds()
+	var result draw64
+	switch img.ColorModel() {
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		result = image.NewNRGBA64(bounds)
+	default:
+		result = nrgba{image.NewNRGBA(bounds)}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			r := in[0][c.R>>4]
+			g := in[1][c.G>>4]
+			b := in[2][c.B>>4]
+			result.SetNRGBA64(x, y, color.NRGBA64{
+				R: encode(m[0][0]*r + m[0][1]*g + m[0][2]*b),
+				G: encode(m[1][0]*r + m[1][1]*g + m[1][2]*b),
+				B: encode(m[2][0]*r + m[2][1]*g + m[2][2]*b),
+				A: c.A,
+			})
+		}
+	}
+	return result
+}
+
+type draw64 interface {
+	image.Image
+	SetNRGBA64(x, y int, c color.NRGBA64)
+}
+
+// nrgba écrit des couleurs 16 bits dans une image 8 bits
+type nrgba struct {
+	*image.NRGBA
+}
+
+func (m nrgba) SetNRGBA64(x, y int, c color.NRGBA64) {
+	m.SetNRGBA(x, y, color.NRGBA{uint8(c.R >> 8), uint8(c.G >> 8), uint8(c.B >> 8), uint8(c.A >> 8)})
+}
+
+func mul(a, b [3][3]float64) [3][3]float64 {
+	var r [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return r
+}
+
+func invert(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	var r [3][3]float64
+	r[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det
+	r[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det
+	r[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det
+	r[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det
+	r[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det
+	r[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det
+	r[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det
+	r[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det
+	r[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det
+	return r
+}
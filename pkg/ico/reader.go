@@ -0,0 +1,286 @@
+// Package ico lit et écrit les icônes Windows (.ico) et curseurs (.cur)
+// contenant plusieurs tailles, en BMP ou PNG.
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+const (
+	typeIcon   = 1
+	typeCursor = 2
+
+	dirSize   = 6
+	entrySize = 16
+)
+
+var (
+	errFormat  = errors.New("ico: fichier ICO/CUR invalide")
+	pngMagic   = []byte("\x89PNG\r\n\x1a\n")
+	errBitmap  = errors.New("ico: bitmap invalide")
+	errEntries = errors.New("ico: aucune image dans le fichier")
+)
+
+func init() {
+	image.RegisterFormat("ico", "\x00\x00\x01\x00", Decode, DecodeConfig)
+	image.RegisterFormat("cur", "\x00\x00\x02\x00", Decode, DecodeConfig)
+}
+
+// Entry décrit une image embarquée dans le fichier
+type Entry struct {
+	Width, Height int
+	BitCount      int
+	PNG           bool
+	// Point actif, pour les curseurs uniquement
+	HotspotX, HotspotY int
+
+	data []byte
+}
+
+// Entries liste les images embarquées avec leurs dimensions réelles
+func Entries(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data)
+}
+
+// Decode lit l'image la plus grande du fichier
+func Decode(r io.Reader) (image.Image, error) {
+	entries, err := Entries(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEntry(entries[Largest(entries)])
+}
+
+// DecodeEntry lit l'image d'index i (dans l'ordre de Entries)
+func DecodeEntry(r io.Reader, i int) (image.Image, error) {
+	entries, err := Entries(r)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(entries) {
+		return nil, fmt.Errorf("ico: image %d inexistante (%d images)", i+1, len(entries))
+	}
+	return decodeEntry(entries[i])
+}
+
+// DecodeConfig renvoie les dimensions de l'image la plus grande
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	entries, err := Entries(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	e := entries[Largest(entries)]
+	return image.Config{ColorModel: color.NRGBAModel, Width: e.Width, Height: e.Height}, nil
+}
+
+// Largest renvoie l'index de la plus grande image, la plus profonde à taille égale
+func Largest(entries []Entry) int {
+	best := 0
+	for i, e := range entries {
+		b := entries[best]
+		if e.Width*e.Height > b.Width*b.Height ||
+			e.Width*e.Height == b.Width*b.Height && e.BitCount > b.BitCount {
+			best = i
+		}
+	}
+	return best
+}
+
+func parse(data []byte) ([]Entry, error) {
+	if len(data) < dirSize {
+		return nil, errFormat
+	}
+	le := binary.LittleEndian
+	typ := le.Uint16(data[2:4])
+	if le.Uint16(data[0:2]) != 0 || (typ != typeIcon && typ != typeCursor) {
+		return nil, errFormat
+	}
+	count := int(le.Uint16(data[4:6]))
+	if len(data) < dirSize+count*entrySize {
+		return nil, errFormat
+	}
+
+	var entries []Entry
+	for i := 0; i < count; i++ {
+		d := data[dirSize+i*entrySize:]
+		size := le.Uint32(d[8:12])
+		offset := le.Uint32(d[12:16])
+		if uint64(offset)+uint64(size) > uint64(len(data)) {
+			return nil, errFormat
+		}
+
+		e := Entry{
+			Width:    int(d[0]),
+			Height:   int(d[1]),
+			BitCount: int(le.Uint16(d[6:8])),
+			data:     data[offset : offset+size],
+		}
+		if typ == typeCursor {
+			e.HotspotX = int(le.Uint16(d[4:6]))
+			e.HotspotY = e.BitCount
+			e.BitCount = 0
+		}
+		if e.Width == 0 {
+			e.Width = 256
+		}
+		if e.Height == 0 {
+			e.Height = 256
+		}
+
+		// Les dimensions réelles priment sur celles du répertoire
+		if bytes.HasPrefix(e.data, pngMagic) {
+			e.PNG = true
+			cfg, err := png.DecodeConfig(bytes.NewReader(e.data))
+			if err != nil {
+				return nil, err
+			}
+			e.Width, e.Height = cfg.Width, cfg.Height
+			if e.BitCount == 0 {
+				e.BitCount = 32
+			}
+		} else if len(e.data) >= 16 {
+			w := int(int32(le.Uint32(e.data[4:8])))
+			h := int(int32(le.Uint32(e.data[8:12])))
+			if w > 0 && h != 0 {
+				e.Width, e.Height = w, abs(h)/2
+			}
+			e.BitCount = int(le.Uint16(e.data[14:16]))
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, errEntries
+	}
+	return entries, nil
+}
+
+func decodeEntry(e Entry) (image.Image, error) {
+	if e.PNG {
+		return png.Decode(bytes.NewReader(e.data))
+	}
+	return decodeDIB(e.data)
+}
+
+// decodeDIB lit un BITMAPINFOHEADER suivi du masque couleur et du masque AND
+func decodeDIB(data []byte) (image.Image, error) {
+	le := binary.LittleEndian
+	if len(data) < 40 {
+		return nil, errBitmap
+	}
+	hdrSize := int(le.Uint32(data[0:4]))
+	w := int(int32(le.Uint32(data[4:8])))
+	fullH := int(int32(le.Uint32(data[8:12])))
+	bpp := int(le.Uint16(data[14:16]))
+	compression := le.Uint32(data[16:20])
+	clrUsed := int(le.Uint32(data[32:36]))
+
+	topDown := fullH < 0
+	h := abs(fullH) / 2
+	if w <= 0 || h <= 0 || w > 1024 || h > 1024 || hdrSize < 40 || hdrSize > len(data) {
+		return nil, errBitmap
+	}
+	if compression != 0 && !(compression == 3 && bpp == 32) {
+		return nil, fmt.Errorf("ico: compression BMP %d non supportée", compression)
+	}
+
+	pos := hdrSize
+	var palette []color.NRGBA
+	if bpp <= 8 {
+		n := clrUsed
+		if n == 0 {
+			n = 1 << bpp
+		}
+		if pos+4*n > len(data) {
+			return nil, errBitmap
+		}
+		for i := 0; i < n; i++ {
+			p := data[pos+4*i:]
+			palette = append(palette, color.NRGBA{p[2], p[1], p[0], 255})
+		}
+		pos += 4 * n
+	} else if compression == 3 {
+		// Masques BI_BITFIELDS supposés BGRA standards
+		pos += 12
+	}
+
+	stride := (w*bpp + 31) / 32 * 4
+	maskStride := (w + 31) / 32 * 4
+	if pos+stride*h > len(data) {
+		return nil, errBitmap
+	}
+	pixels := data[pos : pos+stride*h]
+	var mask []byte
+	if pos+stride*h+maskStride*h <= len(data) {
+		mask = data[pos+stride*h : pos+stride*h+maskStride*h]
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	hasAlpha := false
+	for row := 0; row < h; row++ {
+		y := h - 1 - row
+		if topDown {
+			y = row
+		}
+		line := pixels[row*stride:]
+		for x := 0; x < w; x++ {
+			var c color.NRGBA
+			switch bpp {
+			case 32:
+				c = color.NRGBA{line[4*x+2], line[4*x+1], line[4*x], line[4*x+3]}
+				if c.A != 0 {
+					hasAlpha = true
+				}
+			case 24:
+				c = color.NRGBA{line[3*x+2], line[3*x+1], line[3*x], 255}
+			case 1, 4, 8:
+				bit := x * bpp
+				idx := int(line[bit/8]>>(8-uint(bit%8)-uint(bpp))) & (1<<bpp - 1)
+				if idx < len(palette) {
+					c = palette[idx]
+				}
+			default:
+				return nil, fmt.Errorf("ico: %d bits par pixel non supportés", bpp)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	// Le masque AND ne sert que si l'image n'a pas de canal alpha exploitable
+	if bpp == 32 && hasAlpha || mask == nil {
+		return img, nil
+	}
+	for row := 0; row < h; row++ {
+		y := h - 1 - row
+		if topDown {
+			y = row
+		}
+		line := mask[row*maskStride:]
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			if line[x/8]&(0x80>>uint(x%8)) != 0 {
+				img.Pix[i+3] = 0
+			} else {
+				img.Pix[i+3] = 255
+			}
+		}
+	}
+	return img, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
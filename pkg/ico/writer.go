@@ -0,0 +1,115 @@
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Sizes est la liste des tailles générées par défaut pour un favicon
+var Sizes = []int{16, 32, 48, 64, 128, 256}
+
+// Options contrôle l'écriture; par défaut un fichier .ico est produit
+type Options struct {
+	Cursor             bool
+	HotspotX, HotspotY int
+}
+
+// Encode écrit les images (déjà redimensionnées, 256×256 au maximum) dans
+// un seul fichier. Les images de 256 pixels sont compressées en PNG, les
+// autres stockées en BMP 32 bits avec masque AND.
+func Encode(w io.Writer, imgs []image.Image, o *Options) error {
+	if len(imgs) == 0 {
+		return errEntries
+	}
+	if len(imgs) > 0xffff {
+		return errors.New("ico: trop d'images")
+	}
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+
+	le := binary.LittleEndian
+	var dir, body bytes.Buffer
+	typ := uint16(typeIcon)
+	if opts.Cursor {
+		typ = typeCursor
+	}
+	binary.Write(&dir, le, [3]uint16{0, typ, uint16(len(imgs))})
+
+	offset := uint32(dirSize + entrySize*len(imgs))
+	for _, img := range imgs {
+		b := img.Bounds()
+		if b.Dx() < 1 || b.Dy() < 1 || b.Dx() > 256 || b.Dy() > 256 {
+			return fmt.Errorf("ico: taille %d×%d hors limites (1 à 256)", b.Dx(), b.Dy())
+		}
+
+		var data []byte
+		if b.Dx() >= 256 || b.Dy() >= 256 {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, img); err != nil {
+				return err
+			}
+			data = buf.Bytes()
+		} else {
+			data = encodeDIB(img)
+		}
+
+		// Dans le répertoire, 0 signifie 256
+		entry := [4]uint8{uint8(b.Dx()), uint8(b.Dy()), 0, 0}
+		dir.Write(entry[:])
+		if opts.Cursor {
+			binary.Write(&dir, le, [2]uint16{uint16(opts.HotspotX), uint16(opts.HotspotY)})
+		} else {
+			binary.Write(&dir, le, [2]uint16{1, 32})
+		}
+		binary.Write(&dir, le, [2]uint32{uint32(len(data)), offset})
+
+		body.Write(data)
+		offset += uint32(len(data))
+	}
+
+	if _, err := w.Write(dir.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// encodeDIB produit un bitmap BGRA 32 bits de bas en haut suivi du masque AND
+func encodeDIB(img image.Image) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	maskStride := (w + 31) / 32 * 4
+	le := binary.LittleEndian
+
+	var buf bytes.Buffer
+	hdr := make([]byte, 40)
+	le.PutUint32(hdr[0:4], 40)
+	le.PutUint32(hdr[4:8], uint32(w))
+	le.PutUint32(hdr[8:12], uint32(2*h))
+	le.PutUint16(hdr[12:14], 1)
+	le.PutUint16(hdr[14:16], 32)
+	le.PutUint32(hdr[20:24], uint32(4*w*h+maskStride*h))
+	buf.Write(hdr)
+
+	mask := make([]byte, maskStride*h)
+	for row := 0; row < h; row++ {
+		y := b.Max.Y - 1 - row
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, y)).(color.NRGBA)
+			buf.Write([]byte{c.B, c.G, c.R, c.A})
+			if c.A == 0 {
+				mask[row*maskStride+x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+	}
+	buf.Write(mask)
+	return buf.Bytes()
+}
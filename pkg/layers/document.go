@@ -0,0 +1,170 @@
+// Package layers décrit un document composé de calques superposés
+package layers
+
+import (
+	"errors"
+	"image"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+)
+
+// Layer est un calque: une image posée sur le document à un décalage donné
+type Layer struct {
+	Name  string
+	Image image.Image
+	// Offset place le coin supérieur gauche de l'image dans le document
+	Offset image.Point
+	// Opacity va de 0 (invisible) à 1 (opaque)
+	Opacity float64
+	Mode    effects.BlendMode
+	// Operator est l'opérateur de Porter-Duff appliqué aux pixels que le
+	// calque couvre
+	Operator effects.Operator
+	Visible  bool
+}
+
+// Document empile des calques, du fond vers le dessus
+type Document struct {
+	Width, Height int
+	Layers        []*Layer
+	// Active est l'indice du calque modifié par les effets
+	Active int
+}
+
+// New crée un document d'un seul calque, aux dimensions de l'image de fond
+func New(background image.Image) *Document {
+	b := background.Bounds()
+	d := &Document{Width: b.Dx(), Height: b.Dy()}
+	d.Layers = []*Layer{{
+		Name:    "Arrière-plan",
+		Image:   background,
+		Opacity: 1,
+		Visible: true,
+	}}
+	return d
+}
+
+// Bounds renvoie le rectangle du document, à l'origine
+func (d *Document) Bounds() image.Rectangle {
+	return image.Rect(0, 0, d.Width, d.Height)
+}
+
+// ActiveLayer renvoie le calque actif
+func (d *Document) ActiveLayer() *Layer {
+	return d.Layers[d.Active]
+}
+
+// NewLayer renvoie un calque transparent aux dimensions du document, sans
+// l'ajouter à la pile
+func (d *Document) NewLayer(name string) *Layer {
+	return &Layer{
+		Name:    name,
+		Image:   image.NewRGBA(d.Bounds()),
+		Opacity: 1,
+		Visible: true,
+	}
+}
+
+// Insert place le calque juste au-dessus du calque actif et l'active
+func (d *Document) Insert(l *Layer) {
+	i := d.Active + 1
+	d.Layers = append(d.Layers, nil)
+	copy(d.Layers[i+1:], d.Layers[i:])
+	d.Layers[i] = l
+	d.Active = i
+}
+
+// Duplicate copie le calque actif au-dessus de lui-même
+func (d *Document) Duplicate() {
+	l := *d.ActiveLayer()
+	l.Name += " (copie)"
+	d.Insert(&l)
+}
+
+// Remove supprime le calque d'indice i; le dernier calque ne peut l'être
+func (d *Document) Remove(i int) error {
+	if len(d.Layers) == 1 {
+		return errors.New("layers: impossible de supprimer le dernier calque")
+	}
+	if i < 0 || i >= len(d.Layers) {
+		return errors.New("layers: calque inexistant")
+	}
+	d.Layers = append(d.Layers[:i], d.Layers[i+1:]...)
+	if d.Active >= i && d.Active > 0 {
+		d.Active--
+	}
+	return nil
+}
+
+// Move déplace le calque i de delta positions (positif vers le dessus);
+// le calque actif le reste. Renvoie false si le déplacement sort de la pile
+func (d *Document) Move(i, delta int) bool {
+	j := i + delta
+	if i < 0 || i >= len(d.Layers) || j < 0 || j >= len(d.Layers) || delta == 0 {
+		return false
+	}
+	active := d.Layers[d.Active]
+	l := d.Layers[i]
+	if j > i {
+		copy(d.Layers[i:j], d.Layers[i+1:j+1])
+	} else {
+		copy(d.Layers[j+1:i+1], d.Layers[j:i])
+	}
+	d.Layers[j] = l
+	for k, other := range d.Layers {
+		if other == active {
+			d.Active = k
+		}
+	}
+	return true
+}
+
+// Flatten compose les calques visibles, chacun avec son opacité, son mode
+// de fusion et son opérateur, sur un fond transparent. Un calque unique
+// couvrant le document sans réglage particulier est renvoyé tel quel, sans
+// perte de profondeur
+func (d *Document) Flatten() image.Image {
+	if len(d.Layers) == 1 {
+		l := d.Layers[0]
+		if l.Visible && l.Opacity == 1 && l.Mode == effects.Normal && l.Operator == effects.SrcOver &&
+			l.Offset == (image.Point{}) && l.Image.Bounds().Size() == d.Bounds().Size() {
+			return l.Image
+		}
+	}
+	var canvas image.Image = image.NewRGBA(d.Bounds())
+	for _, l := range d.Layers {
+		if !l.Visible || l.Image == nil || l.Opacity <= 0 {
+			continue
+		}
+		canvas = (&effects.CompositeEffect{
+			Source: l.Image,
+			X:      l.Offset.X,
+			Y:      l.Offset.Y,
+			Compositing: effects.Compositing{
+				Opacity:  l.Opacity,
+				Operator: l.Operator,
+				Mode:     l.Mode,
+			},
+			Clip: true,
+		}).Apply(canvas)
+	}
+	return canvas
+}
+
+// FlattenWith compose le document comme Flatten, le calque actif portant
+// l'image img; le document n'est pas modifié
+func (d *Document) FlattenWith(img image.Image) image.Image {
+	l := *d.ActiveLayer()
+	l.Image = img
+	preview := *d
+	preview.Layers = append([]*Layer(nil), d.Layers...)
+	preview.Layers[d.Active] = &l
+	return preview.Flatten()
+}
+
+// Merge remplace tous les calques par leur composition
+func (d *Document) Merge() {
+	flat := d.Flatten()
+	d.Layers = []*Layer{{Name: "Arrière-plan", Image: flat, Opacity: 1, Visible: true}}
+	d.Active = 0
+}
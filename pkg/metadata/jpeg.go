@@ -0,0 +1,161 @@
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"sort"
+)
+
+// En-têtes des segments APPn reconnus
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	iccHeader  = []byte("ICC_PROFILE\x00")
+)
+
+// Taille maximale des données d'un segment JPEG (longueur sur 16 bits)
+const maxSegment = 0xffff - 2
+
+// ReadJPEG extrait les métadonnées des segments APP1, APP2 et COM
+func ReadJPEG(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil {
+		return nil, err
+	}
+	if soi[0] != 0xff || soi[1] != 0xd8 {
+		return nil, errors.New("metadata: fichier JPEG invalide")
+	}
+
+	m := &Metadata{}
+	iccChunks := map[int][]byte{}
+	for {
+		marker, err := nextMarker(br)
+		if err != nil {
+			return nil, err
+		}
+		// Les métadonnées précèdent toujours les données compressées
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+		if marker >= 0xd0 && marker <= 0xd7 || marker == 0x01 {
+			continue
+		}
+
+		var lenBuf [2]byte
+		if _, err := io.ReadFull(br, lenBuf[:]); err != nil {
+			return nil, err
+		}
+		n := int(binary.BigEndian.Uint16(lenBuf[:])) - 2
+		if n < 0 {
+			return nil, errors.New("metadata: segment JPEG invalide")
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(payload, exifHeader):
+			m.Exif = payload[len(exifHeader):]
+		case marker == 0xe1 && bytes.HasPrefix(payload, xmpHeader):
+			m.XMP = payload[len(xmpHeader):]
+		case marker == 0xe2 && bytes.HasPrefix(payload, iccHeader) && n >= len(iccHeader)+2:
+			// Profil découpé: numéro de morceau puis nombre total
+			seq := int(payload[len(iccHeader)])
+			iccChunks[seq] = payload[len(iccHeader)+2:]
+		case marker == 0xfe:
+			m.Text = append(m.Text, Text{Keyword: "Comment", Value: string(payload)})
+		}
+	}
+
+	if len(iccChunks) > 0 {
+		seqs := make([]int, 0, len(iccChunks))
+		for s := range iccChunks {
+			seqs = append(seqs, s)
+		}
+		sort.Ints(seqs)
+		for _, s := range seqs {
+			m.ICC = append(m.ICC, iccChunks[s]...)
+		}
+	}
+	return m, nil
+}
+
+// nextMarker saute le remplissage 0xFF et renvoie le code du marqueur
+func nextMarker(br *bufio.Reader) (byte, error) {
+	b, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != 0xff {
+		return 0, errors.New("metadata: marqueur JPEG attendu")
+	}
+	for b == 0xff {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+	return b, nil
+}
+
+// EncodeJPEG encode img en JPEG et insère les métadonnées après SOI
+func EncodeJPEG(w io.Writer, img image.Image, o *jpeg.Options, m *Metadata) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, o); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	bw := bufio.NewWriter(w)
+	bw.Write(data[:2])
+	if m != nil {
+		if len(m.Exif) > 0 && len(exifHeader)+len(m.Exif) <= maxSegment {
+			writeSegment(bw, 0xe1, exifHeader, m.Exif)
+		}
+		if len(m.XMP) > 0 && len(xmpHeader)+len(m.XMP) <= maxSegment {
+			writeSegment(bw, 0xe1, xmpHeader, m.XMP)
+		}
+		writeICC(bw, m.ICC)
+		for _, t := range m.Text {
+			value := t.Value
+			if t.Keyword != "Comment" {
+				value = t.Keyword + ": " + value
+			}
+			if len(value) <= maxSegment {
+				writeSegment(bw, 0xfe, nil, []byte(value))
+			}
+		}
+	}
+	bw.Write(data[2:])
+	return bw.Flush()
+}
+
+// writeICC découpe le profil en segments APP2 numérotés
+func writeICC(w *bufio.Writer, icc []byte) {
+	if len(icc) == 0 {
+		return
+	}
+	chunk := maxSegment - len(iccHeader) - 2
+	count := (len(icc) + chunk - 1) / chunk
+	if count > 255 {
+		return
+	}
+	for i := 0; i < count; i++ {
+		part := icc[i*chunk : min(len(icc), (i+1)*chunk)]
+		header := append(append([]byte(nil), iccHeader...), byte(i+1), byte(count))
+		writeSegment(w, 0xe2, header, part)
+	}
+}
+
+func writeSegment(w *bufio.Writer, marker byte, header, data []byte) {
+	n := 2 + len(header) + len(data)
+	w.Write([]byte{0xff, marker, byte(n >> 8), byte(n)})
+	w.Write(header)
+	w.Write(data)
+}
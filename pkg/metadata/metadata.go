@@ -0,0 +1,89 @@
+// Package metadata extrait les métadonnées des fichiers JPEG et PNG (EXIF,
+// profil ICC, XMP, textes) et les réinjecte à l'écriture, avec ou sans
+// données de localisation.
+package metadata
+
+import (
+	"bytes"
+
+	"github.com/nirdeo/goimage/pkg/exif"
+)
+
+// Mode choisit ce qui est conservé à l'écriture
+type Mode int
+
+const (
+	// KeepAll conserve toutes les métadonnées
+	KeepAll Mode = iota
+	// StripAll n'écrit aucune métadonnée
+	StripAll
+	// StripLocation retire les coordonnées GPS et conserve le reste
+	StripLocation
+)
+
+// Text est un commentaire JPEG ou un chunk texte PNG
+type Text struct {
+	Keyword string
+	Value   string
+}
+
+// Metadata regroupe les métadonnées brutes d'une image
+type Metadata struct {
+	// Exif contient la structure TIFF, sans le préfixe "Exif\0\0"
+	Exif []byte
+	// ICC contient le profil de couleurs décompressé
+	ICC []byte
+	// XMP contient le paquet XML
+	XMP  []byte
+	Text []Text
+}
+
+// Empty indique qu'aucune métadonnée n'est présente
+func (m *Metadata) Empty() bool {
+	return m == nil || len(m.Exif) == 0 && len(m.ICC) == 0 && len(m.XMP) == 0 && len(m.Text) == 0
+}
+
+// HasLocation indique la présence de coordonnées GPS (EXIF ou XMP)
+func (m *Metadata) HasLocation() bool {
+	if m == nil {
+		return false
+	}
+	if len(m.Exif) > 0 {
+		if e, err := exif.Parse(m.Exif); err == nil && e.GPS != nil {
+			return true
+		}
+	}
+	return xmpHasGPS(m.XMP)
+}
+
+// Filter renvoie les métadonnées à écrire selon le mode
+func (m *Metadata) Filter(mode Mode) *Metadata {
+	if m == nil || mode == StripAll {
+		return nil
+	}
+	out := &Metadata{
+		Exif: m.Exif,
+		ICC:  m.ICC,
+		XMP:  m.XMP,
+		Text: append([]Text(nil), m.Text...),
+	}
+	if mode == StripLocation {
+		if len(out.Exif) > 0 {
+			stripped, err := exif.StripGPS(out.Exif)
+			if err != nil {
+				// Bloc illisible: impossible de garantir l'absence de GPS
+				stripped = nil
+			}
+			out.Exif = stripped
+		}
+		// Le paquet XMP peut dupliquer la position: il est retiré entier
+		if xmpHasGPS(out.XMP) {
+			out.XMP = nil
+		}
+	}
+	return out
+}
+
+func xmpHasGPS(xmp []byte) bool {
+	return bytes.Contains(xmp, []byte("GPSLatitude")) || bytes.Contains(xmp, []byte("GPSLongitude"))
+}
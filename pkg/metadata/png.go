@@ -0,0 +1,208 @@
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"unicode/utf8"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// Mot-clé iTXt du paquet XMP
+const xmpKeyword = "XML:com.adobe.xmp"
+
+// ReadPNG extrait les chunks eXIf, iCCP, tEXt, zTXt et iTXt
+func ReadPNG(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+	var sig [8]byte
+	if _, err := io.ReadFull(br, sig[:]); err != nil {
+		return nil, err
+	}
+	if string(sig[:]) != pngSignature {
+		return nil, errors.New("metadata: fichier PNG invalide")
+	}
+
+	m := &Metadata{}
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(br, hdr[:]); err != nil {
+			return nil, err
+		}
+		n := binary.BigEndian.Uint32(hdr[:4])
+		typ := string(hdr[4:8])
+		if n > 1<<28 {
+			return nil, errors.New("metadata: chunk PNG trop grand")
+		}
+		// Les données d'image sont ignorées sans être lues en mémoire
+		if typ == "IDAT" {
+			if _, err := br.Discard(int(n) + 4); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		data := make([]byte, n+4)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, err
+		}
+		data = data[:n]
+
+		switch typ {
+		case "IEND":
+			return m, nil
+		case "eXIf":
+			m.Exif = data
+		case "iCCP":
+			// Nom du profil, méthode de compression puis flux zlib
+			if i := bytes.IndexByte(data, 0); i >= 0 && i+2 <= len(data) {
+				if icc, err := inflate(data[i+2:]); err == nil {
+					m.ICC = icc
+				}
+			}
+		case "tEXt":
+			if k, v, ok := bytes.Cut(data, []byte{0}); ok {
+				m.Text = append(m.Text, Text{latin1(k), latin1(v)})
+			}
+		case "zTXt":
+			if k, v, ok := bytes.Cut(data, []byte{0}); ok && len(v) > 0 {
+				if text, err := inflate(v[1:]); err == nil {
+					m.Text = append(m.Text, Text{latin1(k), latin1(text)})
+				}
+			}
+		case "iTXt":
+			if t, ok := parseITXt(data); ok {
+				if t.Keyword == xmpKeyword {
+					m.XMP = []byte(t.Value)
+				} else {
+					m.Text = append(m.Text, t)
+				}
+			}
+		}
+	}
+}
+
+// parseITXt lit mot-clé, drapeau et méthode de compression, langue, mot-clé
+// traduit puis texte UTF-8
+func parseITXt(data []byte) (Text, bool) {
+	k, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || len(rest) < 2 {
+		return Text{}, false
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+	if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+		return Text{}, false
+	}
+	if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+		return Text{}, false
+	}
+	if compressed {
+		var err error
+		if rest, err = inflate(rest); err != nil {
+			return Text{}, false
+		}
+	}
+	return Text{latin1(k), string(rest)}, true
+}
+
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// latin1 convertit un texte ISO 8859-1 en UTF-8
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// toLatin1 renvoie le texte en ISO 8859-1 s'il est représentable
+func toLatin1(s string) ([]byte, bool) {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff || r == utf8.RuneError {
+			return nil, false
+		}
+		out = append(out, byte(r))
+	}
+	return out, true
+}
+
+// EncodePNG encode img en PNG et insère les métadonnées après IHDR
+func EncodePNG(w io.Writer, img image.Image, m *Metadata) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	// Signature (8) puis IHDR (4 + 4 + 13 + 4)
+	ihdrEnd := 8 + 25
+
+	bw := bufio.NewWriter(w)
+	bw.Write(data[:ihdrEnd])
+	if m != nil {
+		if len(m.ICC) > 0 {
+			writeChunk(bw, "iCCP", append([]byte("ICC Profile\x00\x00"), deflate(m.ICC)...))
+		}
+		if len(m.Exif) > 0 {
+			writeChunk(bw, "eXIf", m.Exif)
+		}
+		if len(m.XMP) > 0 {
+			writeChunk(bw, "iTXt", iTXt(xmpKeyword, string(m.XMP)))
+		}
+		for _, t := range m.Text {
+			key, ok := toLatin1(t.Keyword)
+			if !ok || len(key) == 0 || len(key) > 79 {
+				continue
+			}
+			if value, ok := toLatin1(t.Value); ok {
+				writeChunk(bw, "tEXt", append(append(key, 0), value...))
+			} else {
+				writeChunk(bw, "iTXt", iTXt(string(key), t.Value))
+			}
+		}
+	}
+	bw.Write(data[ihdrEnd:])
+	return bw.Flush()
+}
+
+// iTXt construit un chunk texte UTF-8 non compressé, sans langue
+func iTXt(keyword, value string) []byte {
+	out := append([]byte(keyword), 0, 0, 0, 0, 0)
+	return append(out, value...)
+}
+
+func writeChunk(w *bufio.Writer, typ string, data []byte) {
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(data)))
+	copy(hdr[4:], typ)
+	w.Write(hdr[:])
+	w.Write(data)
+	crc := crc32.NewIEEE()
+	crc.Write(hdr[4:])
+	crc.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	w.Write(sum[:])
+}
@@ -0,0 +1,410 @@
+package netpbm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errHeader = errors.New("netpbm: en-tête invalide")
+
+// header décrit l'en-tête commun à toutes les variantes Netpbm
+type header struct {
+	magic    string
+	width    int
+	height   int
+	depth    int
+	maxval   int
+	tupltype string
+}
+
+type decoder struct {
+	r *bufio.Reader
+	h header
+}
+
+func init() {
+	image.RegisterFormat("pbm", "P1", Decode, DecodeConfig)
+	image.RegisterFormat("pbm", "P4", Decode, DecodeConfig)
+	image.RegisterFormat("pgm", "P2", Decode, DecodeConfig)
+	image.RegisterFormat("pgm", "P5", Decode, DecodeConfig)
+	image.RegisterFormat("ppm", "P3", Decode, DecodeConfig)
+	image.RegisterFormat("ppm", "P6", Decode, DecodeConfig)
+	image.RegisterFormat("pam", "P7", Decode, DecodeConfig)
+}
+
+// Decode lit une image PBM, PGM, PPM ou PAM (ASCII ou binaire)
+func Decode(r io.Reader) (image.Image, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	if err := d.readHeader(); err != nil {
+		return nil, err
+	}
+	return d.readPixels()
+}
+
+// DecodeConfig renvoie les dimensions et le modèle de couleur sans décoder les pixels
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	if err := d.readHeader(); err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: d.colorModel(),
+		Width:      d.h.width,
+		Height:     d.h.height,
+	}, nil
+}
+
+func (d *decoder) readHeader() error {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(d.r, magic); err != nil {
+		return err
+	}
+	if magic[0] != 'P' || magic[1] < '1' || magic[1] > '7' {
+		return errHeader
+	}
+	d.h.magic = string(magic)
+
+	if d.h.magic == "P7" {
+		return d.readPAMHeader()
+	}
+
+	var err error
+	if d.h.width, err = d.readInt(); err != nil {
+		return err
+	}
+	if d.h.height, err = d.readInt(); err != nil {
+		return err
+	}
+
+	switch d.h.magic {
+	case "P1", "P4":
+		d.h.maxval = 1
+		d.h.depth = 1
+		d.h.tupltype = "BLACKANDWHITE"
+	case "P2", "P5":
+		d.h.depth = 1
+		d.h.tupltype = "GRAYSCALE"
+	case "P3", "P6":
+		d.h.depth = 3
+		d.h.tupltype = "RGB"
+	}
+
+	if d.h.maxval == 0 {
+		if d.h.maxval, err = d.readInt(); err != nil {
+			return err
+		}
+	}
+
+	return d.checkHeader()
+}
+
+// readPAMHeader lit les lignes clé/valeur jusqu'à ENDHDR
+func (d *decoder) readPAMHeader() error {
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil {
+			return errHeader
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "ENDHDR" {
+			break
+		}
+
+		fields := strings.Fields(line)
+		key := fields[0]
+		value := strings.Join(fields[1:], " ")
+		switch key {
+		case "WIDTH":
+			d.h.width, err = strconv.Atoi(value)
+		case "HEIGHT":
+			d.h.height, err = strconv.Atoi(value)
+		case "DEPTH":
+			d.h.depth, err = strconv.Atoi(value)
+		case "MAXVAL":
+			d.h.maxval, err = strconv.Atoi(value)
+		case "TUPLTYPE":
+			d.h.tupltype = value
+		}
+		if err != nil {
+			return errHeader
+		}
+	}
+
+	// Type implicite selon la profondeur
+	if d.h.tupltype == "" {
+		switch d.h.depth {
+		case 1:
+			d.h.tupltype = "GRAYSCALE"
+		case 2:
+			d.h.tupltype = "GRAYSCALE_ALPHA"
+		case 3:
+			d.h.tupltype = "RGB"
+		case 4:
+			d.h.tupltype = "RGB_ALPHA"
+		}
+	}
+
+	if channelCount(d.h.tupltype) != d.h.depth {
+		return fmt.Errorf("netpbm: TUPLTYPE %q incompatible avec DEPTH %d", d.h.tupltype, d.h.depth)
+	}
+	return d.checkHeader()
+}
+
+// maxPixels borne la taille des images décodées, comme pour TIFF
+const maxPixels = 1 << 28
+
+func (d *decoder) checkHeader() error {
+	if d.h.width <= 0 || d.h.height <= 0 {
+		return errHeader
+	}
+	if d.h.width > 1<<24 || d.h.height > 1<<24 || d.h.width*d.h.height > maxPixels {
+		return fmt.Errorf("netpbm: image trop grande: %d×%d", d.h.width, d.h.height)
+	}
+	if d.h.maxval <= 0 || d.h.maxval > 65535 {
+		return fmt.Errorf("netpbm: valeur maximale invalide: %d", d.h.maxval)
+	}
+	return nil
+}
+
+func channelCount(tupltype string) int {
+	switch tupltype {
+	case "BLACKANDWHITE", "GRAYSCALE":
+		return 1
+	case "BLACKANDWHITE_ALPHA", "GRAYSCALE_ALPHA":
+		return 2
+	case "RGB":
+		return 3
+	case "RGB_ALPHA":
+		return 4
+	}
+	return 0
+}
+
+func (d *decoder) plain() bool {
+	return d.h.magic == "P1" || d.h.magic == "P2" || d.h.magic == "P3"
+}
+
+func (d *decoder) wide() bool {
+	return d.h.maxval > 255
+}
+
+func (d *decoder) hasAlpha() bool {
+	return strings.HasSuffix(d.h.tupltype, "_ALPHA")
+}
+
+func (d *decoder) colorModel() color.Model {
+	gray := d.h.depth <= 2
+	switch {
+	case d.hasAlpha() && d.wide():
+		return color.NRGBA64Model
+	case d.hasAlpha():
+		return color.NRGBAModel
+	case gray && d.wide():
+		return color.Gray16Model
+	case gray:
+		return color.GrayModel
+	case d.wide():
+		return color.RGBA64Model
+	}
+	return color.RGBAModel
+}
+
+func (d *decoder) readPixels() (image.Image, error) {
+	if d.h.magic == "P4" {
+		return d.readRawPBM()
+	}
+
+	rect := image.Rect(0, 0, d.h.width, d.h.height)
+	samples := make([]uint32, d.h.depth)
+	bw := strings.HasPrefix(d.h.tupltype, "BLACKANDWHITE")
+
+	var img image.Image
+	var set func(x, y int)
+
+	switch d.colorModel() {
+	case color.GrayModel:
+		m := image.NewGray(rect)
+		set = func(x, y int) { m.Pix[y*m.Stride+x] = uint8(scale(samples[0], d.h.maxval, 255)) }
+		img = m
+	case color.Gray16Model:
+		m := image.NewGray16(rect)
+		set = func(x, y int) { m.SetGray16(x, y, color.Gray16{uint16(scale(samples[0], d.h.maxval, 65535))}) }
+		img = m
+	case color.RGBAModel:
+		m := image.NewRGBA(rect)
+		set = func(x, y int) {
+			m.SetRGBA(x, y, color.RGBA{
+				uint8(scale(samples[0], d.h.maxval, 255)),
+				uint8(scale(samples[1], d.h.maxval, 255)),
+				uint8(scale(samples[2], d.h.maxval, 255)),
+				255,
+			})
+		}
+		img = m
+	case color.RGBA64Model:
+		m := image.NewRGBA64(rect)
+		set = func(x, y int) {
+			m.SetRGBA64(x, y, color.RGBA64{
+				uint16(scale(samples[0], d.h.maxval, 65535)),
+				uint16(scale(samples[1], d.h.maxval, 65535)),
+				uint16(scale(samples[2], d.h.maxval, 65535)),
+				65535,
+			})
+		}
+		img = m
+	case color.NRGBAModel:
+		m := image.NewNRGBA(rect)
+		set = func(x, y int) {
+			c := d.nrgba64(samples)
+			m.SetNRGBA(x, y, color.NRGBA{uint8(c.R >> 8), uint8(c.G >> 8), uint8(c.B >> 8), uint8(c.A >> 8)})
+		}
+		img = m
+	default:
+		m := image.NewNRGBA64(rect)
+		set = func(x, y int) { m.SetNRGBA64(x, y, d.nrgba64(samples)) }
+		img = m
+	}
+
+	for y := 0; y < d.h.height; y++ {
+		for x := 0; x < d.h.width; x++ {
+			for i := range samples {
+				v, err := d.readSample()
+				if err != nil {
+					return nil, err
+				}
+				// En PBM, 1 = noir
+				if bw && i == 0 && d.h.magic != "P7" {
+					v = 1 - v
+				}
+				samples[i] = v
+			}
+			set(x, y)
+		}
+	}
+	return img, nil
+}
+
+// nrgba64 convertit des échantillons gris+alpha ou RGB+alpha en couleur 16 bits
+func (d *decoder) nrgba64(s []uint32) color.NRGBA64 {
+	max := d.h.maxval
+	if len(s) == 2 {
+		v := uint16(scale(s[0], max, 65535))
+		return color.NRGBA64{v, v, v, uint16(scale(s[1], max, 65535))}
+	}
+	return color.NRGBA64{
+		uint16(scale(s[0], max, 65535)),
+		uint16(scale(s[1], max, 65535)),
+		uint16(scale(s[2], max, 65535)),
+		uint16(scale(s[3], max, 65535)),
+	}
+}
+
+// readRawPBM lit un PBM binaire: 1 bit par pixel, lignes complétées à l'octet
+func (d *decoder) readRawPBM() (image.Image, error) {
+	img := image.NewGray(image.Rect(0, 0, d.h.width, d.h.height))
+	row := make([]byte, (d.h.width+7)/8)
+	for y := 0; y < d.h.height; y++ {
+		if _, err := io.ReadFull(d.r, row); err != nil {
+			return nil, err
+		}
+		for x := 0; x < d.h.width; x++ {
+			if row[x/8]&(0x80>>uint(x%8)) == 0 {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+	}
+	return img, nil
+}
+
+func (d *decoder) readSample() (uint32, error) {
+	switch {
+	case d.h.magic == "P1":
+		// En PBM ASCII les chiffres peuvent être collés
+		b, err := d.skipSpace()
+		if err != nil {
+			return 0, err
+		}
+		if b != '0' && b != '1' {
+			return 0, fmt.Errorf("netpbm: pixel PBM invalide: %q", b)
+		}
+		return uint32(b - '0'), nil
+	case d.plain():
+		v, err := d.readInt()
+		return uint32(v), err
+	case d.wide():
+		var buf [2]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return 0, err
+		}
+		return uint32(buf[0])<<8 | uint32(buf[1]), nil
+	}
+	b, err := d.r.ReadByte()
+	return uint32(b), err
+}
+
+// skipSpace saute les blancs et commentaires et renvoie le premier octet utile
+func (d *decoder) skipSpace() (byte, error) {
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b == '#' {
+			if _, err := d.r.ReadString('\n'); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' && b != '\v' && b != '\f' {
+			return b, nil
+		}
+	}
+}
+
+// readInt lit un entier décimal; le blanc qui le termine est consommé
+func (d *decoder) readInt() (int, error) {
+	b, err := d.skipSpace()
+	if err != nil {
+		return 0, err
+	}
+	if b < '0' || b > '9' {
+		return 0, errHeader
+	}
+	n := 0
+	for b >= '0' && b <= '9' {
+		n = n*10 + int(b-'0')
+		if n > 1<<24 {
+			return 0, errHeader
+		}
+		b, err = d.r.ReadByte()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if b == '#' {
+		d.r.UnreadByte()
+	}
+	return n, nil
+}
+
+func scale(v uint32, maxval int, target uint32) uint32 {
+	if uint32(maxval) == target {
+		return v
+	}
+	if v > uint32(maxval) {
+		v = uint32(maxval)
+	}
+	return (v*target + uint32(maxval)/2) / uint32(maxval)
+}
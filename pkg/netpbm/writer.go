@@ -0,0 +1,281 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+// Format désigne la variante Netpbm à écrire
+type Format int
+
+const (
+	Auto Format = iota // PGM pour le gris, PAM si transparence, PPM sinon
+	PBM
+	PGM
+	PPM
+	PAM
+	PNM // PGM pour le gris, PPM sinon; jamais PAM, la transparence est perdue
+)
+
+// Options contrôle l'encodage Netpbm
+type Options struct {
+	Format Format
+	Plain  bool // ASCII (P1/P2/P3) au lieu du binaire; ignoré pour PAM
+}
+
+// FormatFromExt renvoie la variante correspondant à une extension de fichier
+func FormatFromExt(ext string) Format {
+	switch ext {
+	case ".pbm":
+		return PBM
+	case ".pgm":
+		return PGM
+	case ".ppm":
+		return PPM
+	case ".pam":
+		return PAM
+	case ".pnm":
+		return PNM
+	}
+	return Auto
+}
+
+// Encode écrit img au format Netpbm; la profondeur 16 bits est conservée
+// pour les images Gray16, RGBA64 et NRGBA64
+func Encode(w io.Writer, img image.Image, o *Options) error {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	switch opts.Format {
+	case Auto:
+		opts.Format = autoFormat(img)
+	case PNM:
+		opts.Format = PPM
+		if isGray(img) {
+			opts.Format = PGM
+		}
+	}
+
+	b := img.Bounds()
+	if b.Empty() {
+		return fmt.Errorf("netpbm: image vide")
+	}
+
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw, img: img, plain: opts.Plain, maxval: 255}
+	if is16Bit(img) && opts.Format != PBM {
+		e.maxval = 65535
+	}
+
+	var err error
+	switch opts.Format {
+	case PBM:
+		err = e.writePBM()
+	case PGM:
+		err = e.writePNM(1)
+	case PPM:
+		err = e.writePNM(3)
+	case PAM:
+		err = e.writePAM()
+	default:
+		return fmt.Errorf("netpbm: format inconnu: %d", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func autoFormat(img image.Image) Format {
+	if isGray(img) {
+		return PGM
+	}
+	if o, ok := img.(interface{ Opaque() bool }); ok && !o.Opaque() {
+		return PAM
+	}
+	return PPM
+}
+
+func isGray(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		return true
+	}
+	return false
+}
+
+func is16Bit(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray16, *image.RGBA64, *image.NRGBA64:
+		return true
+	}
+	return false
+}
+
+type encoder struct {
+	w       *bufio.Writer
+	img     image.Image
+	plain   bool
+	maxval  int
+	lineLen int
+}
+
+func (e *encoder) writePBM() error {
+	b := e.img.Bounds()
+	magic := "P4"
+	if e.plain {
+		magic = "P1"
+	}
+	fmt.Fprintf(e.w, "%s\n%d %d\n", magic, b.Dx(), b.Dy())
+
+	row := make([]byte, (b.Dx()+7)/8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// Seuil à mi-gris, 1 = noir
+			gray := color.GrayModel.Convert(e.img.At(x, y)).(color.Gray)
+			black := gray.Y < 128
+			if e.plain {
+				bit := byte('0')
+				if black {
+					bit = '1'
+				}
+				e.writeToken([]byte{bit}, false)
+				continue
+			}
+			if black {
+				i := x - b.Min.X
+				row[i/8] |= 0x80 >> uint(i%8)
+			}
+		}
+		if e.plain {
+			e.endLine()
+		} else if _, err := e.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writePNM(channels int) error {
+	b := e.img.Bounds()
+	magic := "P5"
+	if channels == 3 {
+		magic = "P6"
+	}
+	if e.plain {
+		magic = "P2"
+		if channels == 3 {
+			magic = "P3"
+		}
+	}
+	fmt.Fprintf(e.w, "%s\n%d %d\n%d\n", magic, b.Dx(), b.Dy(), e.maxval)
+
+	samples := make([]uint32, channels)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := e.img.At(x, y)
+			if channels == 1 {
+				samples[0] = uint32(color.Gray16Model.Convert(c).(color.Gray16).Y)
+			} else {
+				// Les formats PNM n'ont pas d'alpha: on écrit les couleurs prémultipliées
+				r, g, bl, _ := c.RGBA()
+				samples[0], samples[1], samples[2] = r, g, bl
+			}
+			if err := e.writeSamples(samples); err != nil {
+				return err
+			}
+		}
+		if e.plain {
+			e.endLine()
+		}
+	}
+	return nil
+}
+
+func (e *encoder) writePAM() error {
+	b := e.img.Bounds()
+	gray := isGray(e.img)
+	depth, tupltype := 4, "RGB_ALPHA"
+	if gray {
+		depth, tupltype = 1, "GRAYSCALE"
+	}
+	fmt.Fprintf(e.w, "P7\nWIDTH %d\nHEIGHT %d\nDEPTH %d\nMAXVAL %d\nTUPLTYPE %s\nENDHDR\n",
+		b.Dx(), b.Dy(), depth, e.maxval, tupltype)
+
+	// PAM est toujours binaire
+	e.plain = false
+	samples := make([]uint32, depth)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := e.img.At(x, y)
+			if gray {
+				samples[0] = uint32(color.Gray16Model.Convert(c).(color.Gray16).Y)
+			} else {
+				n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+				samples[0], samples[1], samples[2], samples[3] = uint32(n.R), uint32(n.G), uint32(n.B), uint32(n.A)
+			}
+			if err := e.writeSamples(samples); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeSamples écrit des échantillons 16 bits réduits à maxval
+func (e *encoder) writeSamples(samples []uint32) error {
+	for _, s := range samples {
+		v := s
+		if e.maxval == 255 {
+			v = s >> 8
+		}
+		switch {
+		case e.plain:
+			e.writeToken(strconv.AppendUint(nil, uint64(v), 10), true)
+		case e.maxval > 255:
+			if err := e.w.WriteByte(byte(v >> 8)); err != nil {
+				return err
+			}
+			if err := e.w.WriteByte(byte(v)); err != nil {
+				return err
+			}
+		default:
+			if err := e.w.WriteByte(byte(v)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeToken écrit une valeur ASCII en limitant les lignes à 70 caractères
+func (e *encoder) writeToken(tok []byte, sep bool) {
+	extra := 0
+	if sep && e.lineLen > 0 {
+		extra = 1
+	}
+	if e.lineLen+extra+len(tok) > 70 {
+		e.endLine()
+		extra = 0
+	}
+	if extra == 1 {
+		e.w.WriteByte(' ')
+	}
+	e.w.Write(tok)
+	e.lineLen += extra + len(tok)
+}
+
+func (e *encoder) endLine() {
+	if e.lineLen > 0 {
+		e.w.WriteByte('\n')
+		e.lineLen = 0
+	}
+}
@@ -0,0 +1,295 @@
+// Package project lit et écrit les projets GoImage (.goimg), qui
+// conservent une session d'édition complète pour la reprendre plus tard.
+//
+// Un projet est une archive zip contenant:
+//
+//	project.json     description du document, des calques et de l'historique
+//	original.png     image telle qu'elle a été chargée
+//	layers/NNN.png   image de chaque calque, du fond vers le dessus
+//	metadata.json    métadonnées de l'image d'origine (EXIF, ICC, XMP, textes)
+//
+// Les images sont en PNG, sans perte et en 16 bits si nécessaire.
+package project
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nirdeo/goimage/pkg/effects"
+	"github.com/nirdeo/goimage/pkg/layers"
+	"github.com/nirdeo/goimage/pkg/metadata"
+)
+
+// Extension des fichiers de projet
+const Extension = ".goimg"
+
+// version du format écrit; les versions plus récentes sont refusées
+const version = 1
+
+// maxPixels borne la taille du document et de chaque image de l'archive,
+// comme pour les lecteurs Netpbm et TIFF
+const maxPixels = 1 << 28
+
+// Edit est une entrée de l'historique des modifications: Action en donne
+// la catégorie (effet, dessin, calque...), Detail l'opération et ses
+// réglages. L'historique se consulte, il ne se rejoue pas
+type Edit struct {
+	Action string    `json:"action"`
+	Detail string    `json:"detail,omitempty"`
+	Layer  string    `json:"layer,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Project regroupe tout ce qu'il faut pour reprendre une session
+type Project struct {
+	Document *layers.Document
+	// Original est l'image telle qu'elle a été chargée, avant modification
+	Original image.Image
+	// Source et Format décrivent le fichier d'origine
+	Source   string
+	Format   string
+	Metadata *metadata.Metadata
+	History  []Edit
+}
+
+type manifest struct {
+	Version int         `json:"version"`
+	Saved   time.Time   `json:"saved"`
+	Source  string      `json:"source,omitempty"`
+	Format  string      `json:"format,omitempty"`
+	Width   int         `json:"width"`
+	Height  int         `json:"height"`
+	Active  int         `json:"active"`
+	Layers  []layerInfo `json:"layers"`
+	History []Edit      `json:"history,omitempty"`
+}
+
+type layerInfo struct {
+	Name    string  `json:"name"`
+	File    string  `json:"file"`
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Opacity float64 `json:"opacity"`
+	// Mode est l'indice du mode de fusion (effects.BlendMode)
+	Mode int `json:"mode"`
+	// Operator est l'indice de l'opérateur de Porter-Duff (effects.Operator),
+	// absent des projets antérieurs
+	Operator int  `json:"operator,omitempty"`
+	Visible  bool `json:"visible"`
+}
+
+// Save écrit le projet dans le fichier path
+func Save(path string, p *Project) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, p); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write écrit le projet sous forme d'archive zip
+func Write(w io.Writer, p *Project) error {
+	doc := p.Document
+	if doc == nil || len(doc.Layers) == 0 {
+		return errors.New("project: document vide")
+	}
+	m := manifest{
+		Version: version,
+		Saved:   time.Now(),
+		Source:  p.Source,
+		Format:  p.Format,
+		Width:   doc.Width,
+		Height:  doc.Height,
+		Active:  doc.Active,
+		History: p.History,
+	}
+	for i, l := range doc.Layers {
+		m.Layers = append(m.Layers, layerInfo{
+			Name:     l.Name,
+			File:     fmt.Sprintf("layers/%03d.png", i),
+			X:        l.Offset.X,
+			Y:        l.Offset.Y,
+			Opacity:  l.Opacity,
+			Mode:     int(l.Mode),
+			Operator: int(l.Operator),
+			Visible:  l.Visible,
+		})
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeJSON(zw, "project.json", m.Saved, m); err != nil {
+		return err
+	}
+	if p.Original != nil {
+		if err := writePNG(zw, "original.png", m.Saved, p.Original); err != nil {
+			return err
+		}
+	}
+	for i, l := range doc.Layers {
+		if err := writePNG(zw, m.Layers[i].File, m.Saved, l.Image); err != nil {
+			return err
+		}
+	}
+	if !p.Metadata.Empty() {
+		if err := writeJSON(zw, "metadata.json", m.Saved, p.Metadata); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, modified time.Time, v interface{}) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writePNG(zw *zip.Writer, name string, modified time.Time, img image.Image) error {
+	// Le PNG est déjà compressé: l'archive le stocke tel quel
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: modified})
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Open lit le projet du fichier path
+func Open(path string) (*Project, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, info.Size())
+}
+
+// Read lit un projet depuis une archive zip de taille size
+func Read(r io.ReaderAt, size int64) (*Project, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("project: archive invalide: %v", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var m manifest
+	if err := readJSON(files, "project.json", &m); err != nil {
+		return nil, err
+	}
+	if m.Version < 1 || m.Version > version {
+		return nil, fmt.Errorf("project: version %d non gérée", m.Version)
+	}
+	if m.Width <= 0 || m.Height <= 0 || len(m.Layers) == 0 || m.Active < 0 || m.Active >= len(m.Layers) {
+		return nil, errors.New("project: description du document invalide")
+	}
+	if uint64(m.Width)*uint64(m.Height) > maxPixels {
+		return nil, fmt.Errorf("project: document trop grand (%d × %d)", m.Width, m.Height)
+	}
+
+	doc := &layers.Document{Width: m.Width, Height: m.Height, Active: m.Active}
+	for _, info := range m.Layers {
+		img, err := readPNG(files, info.File)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode < int(effects.Normal) || info.Mode > int(effects.Difference) {
+			return nil, fmt.Errorf("project: mode de fusion inconnu pour le calque %q", info.Name)
+		}
+		if info.Operator < int(effects.SrcOver) || info.Operator > int(effects.Clear) {
+			return nil, fmt.Errorf("project: opérateur inconnu pour le calque %q", info.Name)
+		}
+		if info.Opacity < 0 || info.Opacity > 1 {
+			return nil, fmt.Errorf("project: opacité invalide pour le calque %q", info.Name)
+		}
+		doc.Layers = append(doc.Layers, &layers.Layer{
+			Name:     info.Name,
+			Image:    img,
+			Offset:   image.Point{info.X, info.Y},
+			Opacity:  info.Opacity,
+			Mode:     effects.BlendMode(info.Mode),
+			Operator: effects.Operator(info.Operator),
+			Visible:  info.Visible,
+		})
+	}
+
+	p := &Project{Document: doc, Source: m.Source, Format: m.Format, History: m.History}
+	if _, ok := files["original.png"]; ok {
+		if p.Original, err = readPNG(files, "original.png"); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := files["metadata.json"]; ok {
+		p.Metadata = &metadata.Metadata{}
+		if err := readJSON(files, "metadata.json", p.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func readJSON(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("project: %s manquant", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("project: %s illisible: %v", name, err)
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("project: %s invalide: %v", name, err)
+	}
+	return nil
+}
+
+func readPNG(files map[string]*zip.File, name string) (image.Image, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("project: %s manquant", name)
+	}
+	// Les dimensions sont vérifiées avant d'allouer l'image
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("project: %s illisible: %v", name, err)
+	}
+	cfg, err := png.DecodeConfig(rc)
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("project: %s invalide: %v", name, err)
+	}
+	if uint64(cfg.Width)*uint64(cfg.Height) > maxPixels {
+		return nil, fmt.Errorf("project: %s trop grand (%d × %d)", name, cfg.Width, cfg.Height)
+	}
+
+	if rc, err = f.Open(); err != nil {
+		return nil, fmt.Errorf("project: %s illisible: %v", name, err)
+	}
+	defer rc.Close()
+	img, err := png.Decode(rc)
+	if err != nil {
+		return nil, fmt.Errorf("project: %s invalide: %v", name, err)
+	}
+	return img, nil
+}
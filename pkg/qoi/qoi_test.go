@@ -0,0 +1,102 @@
+package qoi_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/png"
+	"os"
+	"testing"
+
+	"github.com/nirdeo/goimage/pkg/qoi"
+)
+
+var testImages = []string{"../../test/test_image.png", "../../test/fond_blanc.png"}
+
+func loadPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return img
+}
+
+// withAlpha copie img en NRGBA avec un alpha variable, pour l'encodage à
+// 4 canaux
+func withAlpha(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	out := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			c.A = uint8((x + y) % 256)
+			out.SetNRGBA(x, y, c)
+		}
+	}
+	return out
+}
+
+// roundTrip encode img en QOI, vérifie le nombre de canaux de l'en-tête et
+// décode le résultat via image.Decode
+func roundTrip(t *testing.T, img image.Image, channels byte) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := qoi.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes()[12]; got != channels {
+		t.Fatalf("canaux = %d, attendu %d", got, channels)
+	}
+	out, format, err := image.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "qoi" {
+		t.Fatalf("format = %q, attendu qoi", format)
+	}
+	return out
+}
+
+func assertSamePixels(t *testing.T, want, got image.Image) {
+	t.Helper()
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Size() != gb.Size() {
+		t.Fatalf("dimensions %v, attendu %v", gb.Size(), wb.Size())
+	}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y))
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y))
+			if w != g {
+				t.Fatalf("pixel (%d,%d) = %v, attendu %v", x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestRoundTripOpaque(t *testing.T) {
+	for _, path := range testImages {
+		t.Run(path, func(t *testing.T) {
+			src := loadPNG(t, path)
+			if op, ok := src.(interface{ Opaque() bool }); !ok || !op.Opaque() {
+				t.Skip("image de test non opaque")
+			}
+			assertSamePixels(t, src, roundTrip(t, src, 3))
+		})
+	}
+}
+
+func TestRoundTripAlpha(t *testing.T) {
+	for _, path := range testImages {
+		t.Run(path, func(t *testing.T) {
+			src := withAlpha(loadPNG(t, path))
+			assertSamePixels(t, src, roundTrip(t, src, 4))
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package qoi implémente le format QOI (Quite OK Image), spécification 1.0.
+package qoi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+const (
+	magic      = "qoif"
+	headerSize = 14
+
+	opIndex = 0x00 // 00xxxxxx
+	opDiff  = 0x40 // 01xxxxxx
+	opLuma  = 0x80 // 10xxxxxx
+	opRun   = 0xc0 // 11xxxxxx
+	opRGB   = 0xfe
+	opRGBA  = 0xff
+	opMask  = 0xc0
+
+	// Limite de la spécification pour éviter les allocations démesurées
+	maxPixels = 400000000
+)
+
+var (
+	errHeader = errors.New("qoi: en-tête invalide")
+	padding   = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+)
+
+func init() {
+	image.RegisterFormat("qoi", magic, Decode, DecodeConfig)
+}
+
+type header struct {
+	width, height uint32
+	channels      uint8
+	colorspace    uint8
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return header{}, err
+	}
+	if string(buf[:4]) != magic {
+		return header{}, errHeader
+	}
+	h := header{
+		width:      binary.BigEndian.Uint32(buf[4:8]),
+		height:     binary.BigEndian.Uint32(buf[8:12]),
+		channels:   buf[12],
+		colorspace: buf[13],
+	}
+	if h.width == 0 || h.height == 0 || uint64(h.width)*uint64(h.height) > maxPixels {
+		return header{}, errHeader
+	}
+	if h.channels != 3 && h.channels != 4 || h.colorspace > 1 {
+		return header{}, errHeader
+	}
+	return h, nil
+}
+
+// DecodeConfig renvoie les dimensions de l'image QOI
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{
+		ColorModel: color.NRGBAModel,
+		Width:      int(h.width),
+		Height:     int(h.height),
+	}, nil
+}
+
+func hash(c color.NRGBA) int {
+	return (int(c.R)*3 + int(c.G)*5 + int(c.B)*7 + int(c.A)*11) % 64
+}
+
+// Decode lit une image QOI; les couleurs ne sont pas prémultipliées
+func Decode(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, int(h.width), int(h.height)))
+	var index [64]color.NRGBA
+	px := color.NRGBA{0, 0, 0, 255}
+	run := 0
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		if run > 0 {
+			run--
+		} else {
+			b, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case b == opRGB:
+				if px.R, err = br.ReadByte(); err == nil {
+					if px.G, err = br.ReadByte(); err == nil {
+						px.B, err = br.ReadByte()
+					}
+				}
+			case b == opRGBA:
+				var buf [4]byte
+				_, err = io.ReadFull(br, buf[:])
+				px = color.NRGBA{buf[0], buf[1], buf[2], buf[3]}
+			case b&opMask == opIndex:
+				px = index[b]
+			case b&opMask == opDiff:
+				px.R += (b>>4)&0x03 - 2
+				px.G += (b>>2)&0x03 - 2
+				px.B += b&0x03 - 2
+			case b&opMask == opLuma:
+				var b2 byte
+				b2, err = br.ReadByte()
+				vg := b&0x3f - 32
+				px.R += vg - 8 + (b2>>4)&0x0f
+				px.G += vg
+				px.B += vg - 8 + b2&0x0f
+			case b&opMask == opRun:
+				run = int(b & 0x3f)
+			}
+			if err != nil {
+				return nil, err
+			}
+			index[hash(px)] = px
+		}
+
+		img.Pix[i+0] = px.R
+		img.Pix[i+1] = px.G
+		img.Pix[i+2] = px.B
+		img.Pix[i+3] = px.A
+	}
+	return img, nil
+}
@@ -0,0 +1,98 @@
+package qoi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// Encode écrit img au format QOI. L'alpha n'est écrit que si l'image
+// n'est pas opaque.
+func Encode(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	if b.Empty() {
+		return errors.New("qoi: image vide")
+	}
+	if uint64(b.Dx())*uint64(b.Dy()) > maxPixels {
+		return errors.New("qoi: image trop grande")
+	}
+
+	channels := byte(4)
+	if op, ok := img.(interface{ Opaque() bool }); ok && op.Opaque() {
+		channels = 3
+	}
+
+	bw := bufio.NewWriter(w)
+	var hdr [headerSize]byte
+	copy(hdr[:4], magic)
+	binary.BigEndian.PutUint32(hdr[4:8], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(hdr[8:12], uint32(b.Dy()))
+	hdr[12] = channels
+	hdr[13] = 0 // sRGB avec alpha linéaire
+	bw.Write(hdr[:])
+
+	var index [64]color.NRGBA
+	prev := color.NRGBA{0, 0, 0, 255}
+	run := 0
+	last := b.Dx()*b.Dy() - 1
+	pos := 0
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			px := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if channels == 3 {
+				px.A = 255
+			}
+
+			if px == prev {
+				run++
+				if run == 62 || pos == last {
+					bw.WriteByte(opRun | byte(run-1))
+					run = 0
+				}
+				pos++
+				continue
+			}
+
+			if run > 0 {
+				bw.WriteByte(opRun | byte(run-1))
+				run = 0
+			}
+
+			h := hash(px)
+			switch {
+			case index[h] == px:
+				bw.WriteByte(opIndex | byte(h))
+			case px.A == prev.A:
+				index[h] = px
+				vr := int(int8(px.R - prev.R))
+				vg := int(int8(px.G - prev.G))
+				vb := int(int8(px.B - prev.B))
+				vgr := vr - vg
+				vgb := vb - vg
+
+				switch {
+				case vr > -3 && vr < 2 && vg > -3 && vg < 2 && vb > -3 && vb < 2:
+					bw.WriteByte(opDiff | byte(vr+2)<<4 | byte(vg+2)<<2 | byte(vb+2))
+				case vgr > -9 && vgr < 8 && vg > -33 && vg < 32 && vgb > -9 && vgb < 8:
+					bw.WriteByte(opLuma | byte(vg+32))
+					bw.WriteByte(byte(vgr+8)<<4 | byte(vgb+8))
+				default:
+					bw.Write([]byte{opRGB, px.R, px.G, px.B})
+				}
+			default:
+				index[h] = px
+				bw.Write([]byte{opRGBA, px.R, px.G, px.B, px.A})
+			}
+
+			prev = px
+			pos++
+		}
+	}
+
+	bw.Write(padding)
+	return bw.Flush()
+}
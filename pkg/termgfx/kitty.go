@@ -0,0 +1,41 @@
+package termgfx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"io"
+)
+
+// Taille maximale d'un morceau de données du protocole Kitty
+const kittyChunk = 4096
+
+// EncodeKitty écrit img pour le protocole graphique Kitty: PNG encodé en
+// base64 et découpé en morceaux de 4096 octets
+func EncodeKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	bw := bufio.NewWriter(w)
+	for i := 0; i < len(data); i += kittyChunk {
+		end := min(i+kittyChunk, len(data))
+		more := "1"
+		if end == len(data) {
+			more = "0"
+		}
+		bw.WriteString("\033_G")
+		if i == 0 {
+			// Transmission et affichage immédiat, sans réponse du terminal
+			bw.WriteString("a=T,f=100,q=2,")
+		}
+		bw.WriteString("m=" + more + ";")
+		bw.WriteString(data[i:end])
+		bw.WriteString("\033\\")
+	}
+	return bw.Flush()
+}
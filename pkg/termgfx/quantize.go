@@ -0,0 +1,138 @@
+// Package termgfx encode des images pour les protocoles graphiques des
+// terminaux: Sixel (palette quantifiée) et Kitty (PNG en base64).
+package termgfx
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// Une couleur est réduite à 5 bits par canal pour l'histogramme
+const histBits = 5
+
+type bucket struct {
+	key   uint16
+	count int
+}
+
+// box est un groupe de couleurs de l'histogramme découpé par médiane
+type box struct {
+	entries []bucket
+}
+
+// Quantize calcule une palette d'au plus n couleurs par coupe médiane
+func Quantize(img image.Image, n int) color.Palette {
+	bounds := img.Bounds()
+	hist := make(map[uint16]int)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			hist[key15(img.At(x, y))]++
+		}
+	}
+
+	all := make([]bucket, 0, len(hist))
+	for k, c := range hist {
+		all = append(all, bucket{k, c})
+	}
+	boxes := []box{{all}}
+
+	// On coupe en deux la boîte la plus peuplée le long de son canal le
+	// plus étendu, jusqu'à obtenir n boîtes
+	for len(boxes) < n {
+		best, bestCount := -1, 0
+		for i, b := range boxes {
+			if len(b.entries) < 2 {
+				continue
+			}
+			if c := b.count(); c > bestCount {
+				best, bestCount = i, c
+			}
+		}
+		if best < 0 {
+			break
+		}
+		lo, hi := boxes[best].split()
+		boxes[best] = lo
+		boxes = append(boxes, hi)
+	}
+
+	pal := make(color.Palette, 0, len(boxes))
+	for _, b := range boxes {
+		pal = append(pal, b.average())
+	}
+	return pal
+}
+
+func (b box) count() int {
+	n := 0
+	for _, e := range b.entries {
+		n += e.count
+	}
+	return n
+}
+
+func (b box) split() (box, box) {
+	var min, max [3]int
+	for c := range min {
+		min[c] = 1 << histBits
+	}
+	for _, e := range b.entries {
+		for c, v := range channels(e.key) {
+			if v < min[c] {
+				min[c] = v
+			}
+			if v > max[c] {
+				max[c] = v
+			}
+		}
+	}
+	axis := 0
+	for c := 1; c < 3; c++ {
+		if max[c]-min[c] > max[axis]-min[axis] {
+			axis = c
+		}
+	}
+
+	sort.Slice(b.entries, func(i, j int) bool {
+		return channels(b.entries[i].key)[axis] < channels(b.entries[j].key)[axis]
+	})
+	// Coupe à la médiane des pixels, pas des couleurs distinctes
+	half, acc, cut := b.count()/2, 0, 1
+	for i, e := range b.entries {
+		acc += e.count
+		if acc >= half {
+			cut = i + 1
+			break
+		}
+	}
+	if cut >= len(b.entries) {
+		cut = len(b.entries) - 1
+	}
+	return box{b.entries[:cut]}, box{b.entries[cut:]}
+}
+
+func (b box) average() color.RGBA {
+	var sum [3]int
+	total := 0
+	for _, e := range b.entries {
+		for c, v := range channels(e.key) {
+			sum[c] += v * e.count
+		}
+		total += e.count
+	}
+	expand := func(v int) uint8 {
+		v8 := v * 255 / ((1 << histBits) - 1)
+		return uint8(v8)
+	}
+	return color.RGBA{expand(sum[0] / total), expand(sum[1] / total), expand(sum[2] / total), 255}
+}
+
+func key15(c color.Color) uint16 {
+	r, g, b, _ := c.RGBA()
+	return uint16(r>>11)<<10 | uint16(g>>11)<<5 | uint16(b>>11)
+}
+
+func channels(k uint16) [3]int {
+	return [3]int{int(k >> 10 & 31), int(k >> 5 & 31), int(k & 31)}
+}
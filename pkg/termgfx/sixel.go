@@ -0,0 +1,102 @@
+package termgfx
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// EncodeSixel écrit img en séquence Sixel avec une palette d'au plus
+// colors couleurs (256 au maximum); la transparence n'est pas gérée
+func EncodeSixel(w io.Writer, img image.Image, colors int) error {
+	if colors < 2 || colors > 256 {
+		colors = 256
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil
+	}
+
+	pal := Quantize(img, colors)
+	// Index de palette par couleur 15 bits, calculé à la demande
+	cache := make(map[uint16]uint8)
+	index := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			k := key15(img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			i, ok := cache[k]
+			if !ok {
+				i = uint8(pal.Index(img.At(bounds.Min.X+x, bounds.Min.Y+y)))
+				cache[k] = i
+			}
+			index[y*width+x] = i
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	// Introduction DCS, rapport d'aspect 1:1, puis attributs raster
+	fmt.Fprintf(bw, "\033P0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range pal {
+		r, g, b, _ := c.(color.RGBA).RGBA()
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		// Couleurs présentes dans la bande de 6 lignes
+		var used [256]bool
+		for y := band; y < band+6 && y < height; y++ {
+			for x := 0; x < width; x++ {
+				used[index[y*width+x]] = true
+			}
+		}
+
+		first := true
+		for c := 0; c < len(pal); c++ {
+			if !used[c] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if index[(band+dy)*width+x] == uint8(c) {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = 63 + bits
+			}
+			if !first {
+				// Retour en début de bande pour superposer la couleur suivante
+				bw.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(bw, "#%d", c)
+			writeRuns(bw, row)
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\033\\")
+	return bw.Flush()
+}
+
+// writeRuns compresse les répétitions avec l'introducteur "!"
+func writeRuns(w *bufio.Writer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		n := j - i
+		if n > 3 {
+			fmt.Fprintf(w, "!%d%c", n, row[i])
+		} else {
+			for k := 0; k < n; k++ {
+				w.WriteByte(row[i])
+			}
+		}
+		i = j
+	}
+}
@@ -0,0 +1,325 @@
+// Package tga lit et écrit les images Truevision TGA, brutes ou compressées
+// en RLE.
+package tga
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+const headerSize = 18
+
+// Types d'image
+const (
+	typeColorMapped    = 1
+	typeTrueColor      = 2
+	typeGray           = 3
+	typeColorMappedRLE = 9
+	typeTrueColorRLE   = 10
+	typeGrayRLE        = 11
+)
+
+// Bits du descripteur d'image
+const (
+	descAlphaMask = 0x0f
+	descRightLeft = 0x10
+	descTopDown   = 0x20
+)
+
+// maxPixels borne la taille des images décodées, comme pour les autres
+// formats
+const maxPixels = 1 << 28
+
+var errHeader = errors.New("tga: en-tête invalide")
+
+func init() {
+	// TGA n'a pas de signature: on reconnaît les en-têtes usuels (pas de
+	// table de couleurs, ou table commençant à l'index 0)
+	image.RegisterFormat("tga", "?\x00\x02\x00\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x03\x00\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x0a\x00\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x00\x0b\x00\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x01\x01\x00\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tga", "?\x01\x09\x00\x00", Decode, DecodeConfig)
+}
+
+type header struct {
+	idLength     uint8
+	colorMapType uint8
+	imageType    uint8
+	cmapFirst    uint16
+	cmapLength   uint16
+	cmapDepth    uint8
+	width        uint16
+	height       uint16
+	depth        uint8
+	descriptor   uint8
+}
+
+func readHeader(r io.Reader) (header, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return header{}, err
+	}
+	le := binary.LittleEndian
+	h := header{
+		idLength:     buf[0],
+		colorMapType: buf[1],
+		imageType:    buf[2],
+		cmapFirst:    le.Uint16(buf[3:5]),
+		cmapLength:   le.Uint16(buf[5:7]),
+		cmapDepth:    buf[7],
+		width:        le.Uint16(buf[12:14]),
+		height:       le.Uint16(buf[14:16]),
+		depth:        buf[16],
+		descriptor:   buf[17],
+	}
+	if h.width == 0 || h.height == 0 {
+		return header{}, errHeader
+	}
+	if int(h.width)*int(h.height) > maxPixels {
+		return header{}, fmt.Errorf("tga: image trop grande (%d × %d)", h.width, h.height)
+	}
+
+	switch h.baseType() {
+	case typeColorMapped:
+		if h.colorMapType != 1 || h.depth != 8 || h.cmapLength == 0 {
+			return header{}, errHeader
+		}
+	case typeTrueColor:
+		if h.depth != 15 && h.depth != 16 && h.depth != 24 && h.depth != 32 {
+			return header{}, fmt.Errorf("tga: profondeur %d non supportée", h.depth)
+		}
+	case typeGray:
+		if h.depth != 8 && h.depth != 16 {
+			return header{}, fmt.Errorf("tga: profondeur %d non supportée", h.depth)
+		}
+	default:
+		return header{}, fmt.Errorf("tga: type d'image %d non supporté", h.imageType)
+	}
+	return h, nil
+}
+
+// baseType renvoie le type sans la compression RLE
+func (h header) baseType() uint8 {
+	if h.imageType >= typeColorMappedRLE {
+		return h.imageType - 8
+	}
+	return h.imageType
+}
+
+func (h header) rle() bool {
+	return h.imageType >= typeColorMappedRLE
+}
+
+func (h header) alphaBits() int {
+	return int(h.descriptor & descAlphaMask)
+}
+
+func (h header) colorModel(palette color.Palette) color.Model {
+	switch h.baseType() {
+	case typeColorMapped:
+		return palette
+	case typeGray:
+		if h.depth == 16 {
+			return color.NRGBAModel
+		}
+		return color.GrayModel
+	}
+	if h.depth == 24 || h.alphaBits() == 0 && h.depth != 32 {
+		return color.RGBAModel
+	}
+	return color.NRGBAModel
+}
+
+// DecodeConfig renvoie les dimensions de l'image TGA
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	var model color.Model = h.colorModel(nil)
+	if h.baseType() == typeColorMapped {
+		model = color.NRGBAModel
+	}
+	return image.Config{ColorModel: model, Width: int(h.width), Height: int(h.height)}, nil
+}
+
+// Decode lit une image TGA quel que soit son sens de stockage
+func Decode(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := br.Discard(int(h.idLength)); err != nil {
+		return nil, err
+	}
+
+	// Table de couleurs, lue même si l'image n'est pas indexée
+	var palette color.Palette
+	if h.colorMapType == 1 {
+		entry := (int(h.cmapDepth) + 7) / 8
+		raw := make([]byte, int(h.cmapLength)*entry)
+		if _, err := io.ReadFull(br, raw); err != nil {
+			return nil, err
+		}
+		if h.baseType() == typeColorMapped {
+			// Palette complète: tout index 8 bits reste valide
+			palette = make(color.Palette, 256)
+			for i := range palette {
+				palette[i] = color.NRGBA{0, 0, 0, 255}
+			}
+			for i := 0; i < int(h.cmapLength) && int(h.cmapFirst)+i < 256; i++ {
+				palette[int(h.cmapFirst)+i] = readColor(raw[i*entry:], int(h.cmapDepth), 8)
+			}
+		}
+	}
+
+	bpp := (int(h.depth) + 7) / 8
+	w, ht := int(h.width), int(h.height)
+	// Le tampon grandit au fil des données lues: un en-tête annonçant une
+	// grande image ne suffit pas à réserver toute la mémoire
+	var data []byte
+	if h.rle() {
+		data, err = readRLE(br, w*ht*bpp, bpp)
+	} else {
+		data, err = io.ReadAll(io.LimitReader(br, int64(w*ht*bpp)))
+		if err == nil && len(data) < w*ht*bpp {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rect := image.Rect(0, 0, w, ht)
+	var img image.Image
+	var set func(x, y int, p []byte)
+	switch m := h.colorModel(palette).(type) {
+	case color.Palette:
+		pm := image.NewPaletted(rect, m)
+		set = func(x, y int, p []byte) { pm.SetColorIndex(x, y, p[0]) }
+		img = pm
+	default:
+		switch m {
+		case color.GrayModel:
+			gm := image.NewGray(rect)
+			set = func(x, y int, p []byte) { gm.Pix[y*gm.Stride+x] = p[0] }
+			img = gm
+		case color.RGBAModel:
+			rm := image.NewRGBA(rect)
+			set = func(x, y int, p []byte) {
+				c := readColor(p, int(h.depth), 0).(color.NRGBA)
+				rm.SetRGBA(x, y, color.RGBA{c.R, c.G, c.B, 255})
+			}
+			img = rm
+		default:
+			nm := image.NewNRGBA(rect)
+			set = func(x, y int, p []byte) {
+				if h.baseType() == typeGray {
+					nm.SetNRGBA(x, y, color.NRGBA{p[0], p[0], p[0], p[1]})
+					return
+				}
+				nm.SetNRGBA(x, y, readColor(p, int(h.depth), h.alphaBits()).(color.NRGBA))
+			}
+			img = nm
+		}
+	}
+
+	for row := 0; row < ht; row++ {
+		y := ht - 1 - row
+		if h.descriptor&descTopDown != 0 {
+			y = row
+		}
+		for col := 0; col < w; col++ {
+			x := col
+			if h.descriptor&descRightLeft != 0 {
+				x = w - 1 - col
+			}
+			i := (row*w + col) * bpp
+			set(x, y, data[i:i+bpp])
+		}
+	}
+
+	// Beaucoup d'outils écrivent un alpha nul sans le déclarer: image opaque
+	if nm, ok := img.(*image.NRGBA); ok && h.baseType() == typeTrueColor && allTransparent(nm) {
+		for i := 3; i < len(nm.Pix); i += 4 {
+			nm.Pix[i] = 255
+		}
+	}
+	return img, nil
+}
+
+func allTransparent(m *image.NRGBA) bool {
+	for i := 3; i < len(m.Pix); i += 4 {
+		if m.Pix[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// readColor décode une couleur BGR(A) stockée sur 15, 16, 24 ou 32 bits
+func readColor(p []byte, depth int, alphaBits int) color.Color {
+	switch depth {
+	case 15, 16:
+		v := uint16(p[0]) | uint16(p[1])<<8
+		c := color.NRGBA{
+			R: expand5(uint8(v >> 10 & 0x1f)),
+			G: expand5(uint8(v >> 5 & 0x1f)),
+			B: expand5(uint8(v & 0x1f)),
+			A: 255,
+		}
+		if depth == 16 && alphaBits > 0 && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 24:
+		return color.NRGBA{p[2], p[1], p[0], 255}
+	case 32:
+		return color.NRGBA{p[2], p[1], p[0], p[3]}
+	}
+	return color.NRGBA{A: 255}
+}
+
+func expand5(v uint8) uint8 {
+	return v<<3 | v>>2
+}
+
+// readRLE décompresse size octets de paquets RLE, qui peuvent franchir les
+// fins de ligne
+func readRLE(r *bufio.Reader, size, bpp int) ([]byte, error) {
+	dst := make([]byte, 0, min(size, 1<<16))
+	pixel := make([]byte, bpp)
+	for len(dst) < size {
+		hdr, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		count := int(hdr&0x7f) + 1
+		if len(dst)+count*bpp > size {
+			count = (size - len(dst)) / bpp
+		}
+		if hdr&0x80 != 0 {
+			if _, err := io.ReadFull(r, pixel); err != nil {
+				return nil, err
+			}
+			for i := 0; i < count; i++ {
+				dst = append(dst, pixel...)
+			}
+		} else {
+			pos := len(dst)
+			dst = append(dst, make([]byte, count*bpp)...)
+			if _, err := io.ReadFull(r, dst[pos:]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,198 @@
+package tga
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"io"
+)
+
+// Options contrôle l'écriture TGA
+type Options struct {
+	// Depth vaut 8 (indexé, ou gris pour une image Gray), 16, 24 ou 32;
+	// 0 choisit selon l'image source
+	Depth int
+	RLE   bool
+	// TopLeft place l'origine en haut à gauche au lieu du bas à gauche
+	TopLeft bool
+}
+
+// Encode écrit img au format TGA
+func Encode(w io.Writer, img image.Image, o *Options) error {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	b := img.Bounds()
+	if b.Empty() || b.Dx() > 0xffff || b.Dy() > 0xffff {
+		return errors.New("tga: dimensions invalides")
+	}
+
+	_, isGray := img.(*image.Gray)
+	depth := opts.Depth
+	if depth == 0 {
+		depth = autoDepth(img)
+	}
+
+	h := header{
+		width:  uint16(b.Dx()),
+		height: uint16(b.Dy()),
+		depth:  uint8(depth),
+	}
+
+	// Les pixels sont produits dans l'ordre du fichier
+	var pal color.Palette
+	var src image.Image = img
+	switch depth {
+	case 8:
+		if isGray {
+			h.imageType = typeGray
+			break
+		}
+		h.imageType = typeColorMapped
+		h.colorMapType = 1
+		h.cmapDepth = 32
+		paletted, ok := img.(*image.Paletted)
+		if !ok || len(paletted.Palette) > 256 {
+			paletted = image.NewPaletted(b, palette.Plan9)
+			draw.FloydSteinberg.Draw(paletted, b, img, b.Min)
+		}
+		pal = paletted.Palette
+		h.cmapLength = uint16(len(pal))
+		src = paletted
+	case 16:
+		h.imageType = typeTrueColor
+		h.descriptor = 1
+	case 24:
+		h.imageType = typeTrueColor
+	case 32:
+		h.imageType = typeTrueColor
+		h.descriptor = 8
+	default:
+		return fmt.Errorf("tga: profondeur %d non supportée", depth)
+	}
+	if opts.RLE {
+		h.imageType += 8
+	}
+	if opts.TopLeft {
+		h.descriptor |= descTopDown
+	}
+
+	bw := bufio.NewWriter(w)
+	writeHeader(bw, h)
+	for _, c := range pal {
+		n := color.NRGBAModel.Convert(c).(color.NRGBA)
+		bw.Write([]byte{n.B, n.G, n.R, n.A})
+	}
+
+	bpp := (depth + 7) / 8
+	line := make([]byte, b.Dx()*bpp)
+	for row := 0; row < b.Dy(); row++ {
+		y := b.Max.Y - 1 - row
+		if opts.TopLeft {
+			y = b.Min.Y + row
+		}
+		for x := 0; x < b.Dx(); x++ {
+			putPixel(line[x*bpp:], src, b.Min.X+x, y, depth, isGray)
+		}
+		if opts.RLE {
+			writeRLE(bw, line, bpp)
+		} else {
+			bw.Write(line)
+		}
+	}
+
+	// Pied de page TGA 2.0 sans zones d'extension
+	bw.Write(make([]byte, 8))
+	bw.WriteString("TRUEVISION-XFILE.\x00")
+	return bw.Flush()
+}
+
+func autoDepth(img image.Image) int {
+	switch m := img.(type) {
+	case *image.Gray:
+		return 8
+	case *image.Paletted:
+		if len(m.Palette) <= 256 {
+			return 8
+		}
+	}
+	if op, ok := img.(interface{ Opaque() bool }); ok && op.Opaque() {
+		return 24
+	}
+	return 32
+}
+
+func writeHeader(w io.Writer, h header) {
+	var buf [headerSize]byte
+	le := binary.LittleEndian
+	buf[0] = h.idLength
+	buf[1] = h.colorMapType
+	buf[2] = h.imageType
+	le.PutUint16(buf[3:5], h.cmapFirst)
+	le.PutUint16(buf[5:7], h.cmapLength)
+	buf[7] = h.cmapDepth
+	le.PutUint16(buf[12:14], h.width)
+	le.PutUint16(buf[14:16], h.height)
+	buf[16] = h.depth
+	buf[17] = h.descriptor
+	w.Write(buf[:])
+}
+
+func putPixel(dst []byte, img image.Image, x, y, depth int, isGray bool) {
+	switch {
+	case depth == 8 && isGray:
+		dst[0] = img.(*image.Gray).GrayAt(x, y).Y
+	case depth == 8:
+		dst[0] = img.(*image.Paletted).ColorIndexAt(x, y)
+	default:
+		c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		switch depth {
+		case 16:
+			v := uint16(c.R>>3)<<10 | uint16(c.G>>3)<<5 | uint16(c.B>>3)
+			if c.A >= 128 {
+				v |= 0x8000
+			}
+			dst[0], dst[1] = byte(v), byte(v>>8)
+		case 24:
+			dst[0], dst[1], dst[2] = c.B, c.G, c.R
+		case 32:
+			dst[0], dst[1], dst[2], dst[3] = c.B, c.G, c.R, c.A
+		}
+	}
+}
+
+// writeRLE compresse une ligne en paquets répétés ou bruts de 128 pixels au plus
+func writeRLE(w *bufio.Writer, line []byte, bpp int) {
+	n := len(line) / bpp
+	px := func(i int) []byte { return line[i*bpp : (i+1)*bpp] }
+
+	for i := 0; i < n; {
+		run := 1
+		for i+run < n && run < 128 && bytes.Equal(px(i+run), px(i)) {
+			run++
+		}
+		if run >= 2 {
+			w.WriteByte(0x80 | byte(run-1))
+			w.Write(px(i))
+			i += run
+			continue
+		}
+
+		start := i
+		for i < n && i-start < 128 {
+			if i+1 < n && bytes.Equal(px(i), px(i+1)) {
+				break
+			}
+			i++
+		}
+		w.WriteByte(byte(i - start - 1))
+		w.Write(line[start*bpp : i*bpp])
+	}
+}
@@ -0,0 +1,95 @@
+package tiff
+
+// Types de champs IFD
+const (
+	dtByte     = 1
+	dtASCII    = 2
+	dtShort    = 3
+	dtLong     = 4
+	dtRational = 5
+	dtSByte    = 6
+	dtUndef    = 7
+	dtSShort   = 8
+	dtSLong    = 9
+)
+
+// Taille en octets de chaque type de champ
+var typeSizes = map[uint16]uint32{
+	dtByte:     1,
+	dtASCII:    1,
+	dtShort:    2,
+	dtLong:     4,
+	dtRational: 8,
+	dtSByte:    1,
+	dtUndef:    1,
+	dtSShort:   2,
+	dtSLong:    4,
+	10:         8, // SRATIONAL
+	11:         4, // FLOAT
+	12:         8, // DOUBLE
+}
+
+// Tags utilisés par le codec
+const (
+	tImageWidth      = 256
+	tImageLength     = 257
+	tBitsPerSample   = 258
+	tCompression     = 259
+	tPhotometric     = 262
+	tStripOffsets    = 273
+	tSamplesPerPixel = 277
+	tRowsPerStrip    = 278
+	tStripByteCounts = 279
+	tXResolution     = 282
+	tYResolution     = 283
+	tPlanarConfig    = 284
+	tResolutionUnit  = 296
+	tPredictor       = 317
+	tColorMap        = 320
+	tTileWidth       = 322
+	tTileLength      = 323
+	tTileOffsets     = 324
+	tTileByteCounts  = 325
+	tExtraSamples    = 338
+)
+
+// Interprétation photométrique
+const (
+	pWhiteIsZero = 0
+	pBlackIsZero = 1
+	pRGB         = 2
+	pPaletted    = 3
+)
+
+// Valeurs de ExtraSamples
+const (
+	extraUnspecified  = 0
+	extraAssociated   = 1
+	extraUnassociated = 2
+)
+
+// Compression désigne l'algorithme de compression des bandes
+type Compression uint16
+
+const (
+	Uncompressed Compression = 1
+	LZW          Compression = 5
+	Deflate      Compression = 8
+	PackBits     Compression = 32773
+
+	deflateOld Compression = 32946
+)
+
+func (c Compression) String() string {
+	switch c {
+	case 0, Uncompressed:
+		return "Aucune"
+	case LZW:
+		return "LZW"
+	case Deflate, deflateOld:
+		return "Deflate"
+	case PackBits:
+		return "PackBits"
+	}
+	return "Inconnue"
+}
@@ -0,0 +1,163 @@
+package tiff
+
+import "errors"
+
+// Le LZW de TIFF diffère de compress/lzw: codes écrits MSB en premier et
+// changement de largeur anticipé d'un code ("early change"), comme libtiff.
+
+const (
+	lzwClear    = 256
+	lzwEOI      = 257
+	lzwFirst    = 258
+	lzwMinWidth = 9
+	lzwMaxWidth = 12
+	lzwMaxCode  = 1<<lzwMaxWidth - 1
+)
+
+var errLZW = errors.New("tiff: données LZW corrompues")
+
+// lzwDecode décompresse src; size est une indication de la taille attendue
+func lzwDecode(src []byte, size int) ([]byte, error) {
+	var (
+		prefix [lzwMaxCode + 1]uint16
+		suffix [lzwMaxCode + 1]byte
+		first  [lzwMaxCode + 1]byte
+		length [lzwMaxCode + 1]int
+		stack  [lzwMaxCode + 1]byte
+	)
+	for i := 0; i < 256; i++ {
+		suffix[i] = byte(i)
+		first[i] = byte(i)
+		length[i] = 1
+	}
+
+	dst := make([]byte, 0, size)
+	var bits uint32
+	var nbits uint
+	pos := 0
+	width := uint(lzwMinWidth)
+	next := lzwFirst
+	prev := -1
+
+	for {
+		for nbits < width {
+			if pos >= len(src) {
+				// Certains encodeurs omettent EOI
+				return dst, nil
+			}
+			bits = bits<<8 | uint32(src[pos])
+			pos++
+			nbits += 8
+		}
+		code := int(bits>>(nbits-width)) & (1<<width - 1)
+		nbits -= width
+
+		switch {
+		case code == lzwClear:
+			width = lzwMinWidth
+			next = lzwFirst
+			prev = -1
+			continue
+		case code == lzwEOI:
+			return dst, nil
+		case prev == -1:
+			if code > 255 {
+				return nil, errLZW
+			}
+			dst = append(dst, byte(code))
+			prev = code
+			continue
+		case code > next || next > lzwMaxCode:
+			return nil, errLZW
+		}
+
+		// Nouvelle entrée: chaîne précédente + premier octet de la chaîne courante
+		c := first[prev]
+		if code < next {
+			c = first[code]
+		}
+		prefix[next] = uint16(prev)
+		suffix[next] = c
+		first[next] = first[prev]
+		length[next] = length[prev] + 1
+		next++
+		if next >= 1<<width-1 && width < lzwMaxWidth {
+			width++
+		}
+
+		// Déroulement de la chaîne du code courant
+		n := length[code]
+		for i, k := n-1, code; i >= 0; i-- {
+			stack[i] = suffix[k]
+			k = int(prefix[k])
+		}
+		dst = append(dst, stack[:n]...)
+		prev = code
+	}
+}
+
+type lzwBitWriter struct {
+	dst   []byte
+	bits  uint32
+	nbits uint
+}
+
+func (w *lzwBitWriter) write(code int, width uint) {
+	w.bits = w.bits<<width | uint32(code)
+	w.nbits += width
+	for w.nbits >= 8 {
+		w.dst = append(w.dst, byte(w.bits>>(w.nbits-8)))
+		w.nbits -= 8
+	}
+}
+
+func (w *lzwBitWriter) flush() []byte {
+	if w.nbits > 0 {
+		w.dst = append(w.dst, byte(w.bits<<(8-w.nbits)))
+		w.nbits = 0
+	}
+	return w.dst
+}
+
+// lzwEncode compresse src avec les règles de largeur de libtiff
+func lzwEncode(src []byte) []byte {
+	w := &lzwBitWriter{}
+	width := uint(lzwMinWidth)
+	w.write(lzwClear, width)
+	if len(src) == 0 {
+		w.write(lzwEOI, width)
+		return w.flush()
+	}
+
+	dict := make(map[uint32]int, lzwMaxCode)
+	next := lzwFirst
+	ent := int(src[0])
+	for _, c := range src[1:] {
+		key := uint32(ent)<<8 | uint32(c)
+		if code, ok := dict[key]; ok {
+			ent = code
+			continue
+		}
+		w.write(ent, width)
+		dict[key] = next
+		next++
+		if next == lzwMaxCode-1 {
+			w.write(lzwClear, width)
+			dict = make(map[uint32]int, lzwMaxCode)
+			next = lzwFirst
+			width = lzwMinWidth
+		} else if next > 1<<width-1 {
+			width++
+		}
+		ent = int(c)
+	}
+
+	// Le décodeur ajoutera une entrée en lisant le dernier code
+	w.write(ent, width)
+	next++
+	if next > 1<<width-1 && width < lzwMaxWidth {
+		width++
+	}
+	w.write(lzwEOI, width)
+	return w.flush()
+}
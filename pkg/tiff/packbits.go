@@ -0,0 +1,61 @@
+package tiff
+
+import "errors"
+
+var errPackBits = errors.New("tiff: données PackBits corrompues")
+
+// unpackBits décompresse des données PackBits (Apple) jusqu'à obtenir size octets
+func unpackBits(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+	for i := 0; i < len(src) && len(dst) < size; {
+		n := int(int8(src[i]))
+		i++
+		switch {
+		case n >= 0:
+			// Copie littérale de n+1 octets
+			if i+n+1 > len(src) {
+				return nil, errPackBits
+			}
+			dst = append(dst, src[i:i+n+1]...)
+			i += n + 1
+		case n != -128:
+			// Répétition du prochain octet 1-n fois
+			if i >= len(src) {
+				return nil, errPackBits
+			}
+			for k := 0; k < 1-n; k++ {
+				dst = append(dst, src[i])
+			}
+			i++
+		}
+	}
+	return dst, nil
+}
+
+// packBits compresse une ligne; TIFF impose de ne pas franchir les fins de ligne
+func packBits(dst, src []byte) []byte {
+	for i := 0; i < len(src); {
+		// Longueur de la répétition courante
+		run := 1
+		for i+run < len(src) && run < 128 && src[i+run] == src[i] {
+			run++
+		}
+		if run >= 2 {
+			dst = append(dst, byte(1-run), src[i])
+			i += run
+			continue
+		}
+
+		// Séquence littérale jusqu'à la prochaine répétition
+		start := i
+		for i < len(src) && i-start < 128 {
+			if i+1 < len(src) && src[i] == src[i+1] {
+				break
+			}
+			i++
+		}
+		dst = append(dst, byte(i-start-1))
+		dst = append(dst, src[start:i]...)
+	}
+	return dst
+}
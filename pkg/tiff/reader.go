@@ -0,0 +1,519 @@
+package tiff
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+var errFormat = errors.New("tiff: fichier TIFF invalide")
+
+// maxPixels borne la taille de l'image et de chacune de ses tuiles
+const maxPixels = 1 << 28
+
+func init() {
+	image.RegisterFormat("tiff", "II*\x00", Decode, DecodeConfig)
+	image.RegisterFormat("tiff", "MM\x00*", Decode, DecodeConfig)
+}
+
+type decoder struct {
+	data []byte
+	bo   binary.ByteOrder
+	tags map[uint16][]uint
+
+	width, height int
+	bits          uint
+	spp           int
+	photometric   uint
+	compression   Compression
+	predictor     uint
+	alpha         uint
+	palette       color.Palette
+}
+
+// Decode lit la première page d'un fichier TIFF
+func Decode(r io.Reader) (image.Image, error) {
+	return DecodePage(r, 0)
+}
+
+// DecodeConfig renvoie les dimensions et le modèle de couleur de la première page
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	d, err := newDecoder(r, 0)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: d.colorModel(), Width: d.width, Height: d.height}, nil
+}
+
+// PageCount renvoie le nombre de pages (IFD) du fichier
+func PageCount(r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	_, offsets, err := readIFDOffsets(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(offsets), nil
+}
+
+// DecodePage lit la page d'index donné (à partir de 0)
+func DecodePage(r io.Reader, page int) (image.Image, error) {
+	d, err := newDecoder(r, page)
+	if err != nil {
+		return nil, err
+	}
+	return d.decode()
+}
+
+func newDecoder(r io.Reader, page int) (*decoder, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	bo, offsets, err := readIFDOffsets(data)
+	if err != nil {
+		return nil, err
+	}
+	if page < 0 || page >= len(offsets) {
+		return nil, fmt.Errorf("tiff: page %d inexistante (%d pages)", page+1, len(offsets))
+	}
+
+	d := &decoder{data: data, bo: bo}
+	if err := d.readIFD(offsets[page]); err != nil {
+		return nil, err
+	}
+	if err := d.parseTags(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// readIFDOffsets lit l'en-tête et parcourt la chaîne des IFD
+func readIFDOffsets(data []byte) (binary.ByteOrder, []uint32, error) {
+	if len(data) < 8 {
+		return nil, nil, errFormat
+	}
+	var bo binary.ByteOrder
+	switch string(data[:4]) {
+	case "II*\x00":
+		bo = binary.LittleEndian
+	case "MM\x00*":
+		bo = binary.BigEndian
+	default:
+		return nil, nil, errFormat
+	}
+
+	var offsets []uint32
+	seen := make(map[uint32]bool)
+	for off := bo.Uint32(data[4:8]); off != 0; {
+		// Protection contre les boucles et offsets hors fichier
+		if seen[off] || int64(off)+2 > int64(len(data)) {
+			break
+		}
+		seen[off] = true
+
+		n := int64(bo.Uint16(data[off:]))
+		end := int64(off) + 2 + n*12
+		if end+4 > int64(len(data)) {
+			break
+		}
+		offsets = append(offsets, off)
+		off = bo.Uint32(data[end:])
+	}
+	if len(offsets) == 0 {
+		return nil, nil, errFormat
+	}
+	return bo, offsets, nil
+}
+
+// readIFD charge les champs entiers d'un IFD
+func (d *decoder) readIFD(off uint32) error {
+	d.tags = make(map[uint16][]uint)
+	n := int(d.bo.Uint16(d.data[off:]))
+	for i := 0; i < n; i++ {
+		e := d.data[int(off)+2+i*12:]
+		tag := d.bo.Uint16(e[0:2])
+		typ := d.bo.Uint16(e[2:4])
+		count := d.bo.Uint32(e[4:8])
+
+		size, ok := typeSizes[typ]
+		if !ok {
+			continue
+		}
+		total := uint64(size) * uint64(count)
+		var raw []byte
+		if total <= 4 {
+			raw = e[8 : 8+total]
+		} else {
+			voff := uint64(d.bo.Uint32(e[8:12]))
+			if voff+total > uint64(len(d.data)) {
+				return fmt.Errorf("tiff: valeur du tag %d hors du fichier", tag)
+			}
+			raw = d.data[voff : voff+total]
+		}
+
+		switch typ {
+		case dtByte, dtSByte, dtUndef:
+			vals := make([]uint, count)
+			for j := range vals {
+				vals[j] = uint(raw[j])
+			}
+			d.tags[tag] = vals
+		case dtShort, dtSShort:
+			vals := make([]uint, count)
+			for j := range vals {
+				vals[j] = uint(d.bo.Uint16(raw[2*j:]))
+			}
+			d.tags[tag] = vals
+		case dtLong, dtSLong:
+			vals := make([]uint, count)
+			for j := range vals {
+				vals[j] = uint(d.bo.Uint32(raw[4*j:]))
+			}
+			d.tags[tag] = vals
+		}
+	}
+	return nil
+}
+
+func (d *decoder) first(tag uint16, def uint) uint {
+	if v, ok := d.tags[tag]; ok && len(v) > 0 {
+		return v[0]
+	}
+	return def
+}
+
+func (d *decoder) parseTags() error {
+	d.width = int(d.first(tImageWidth, 0))
+	d.height = int(d.first(tImageLength, 0))
+	if d.width <= 0 || d.height <= 0 || uint64(d.width)*uint64(d.height) > maxPixels {
+		return fmt.Errorf("tiff: dimensions invalides %d×%d", d.width, d.height)
+	}
+
+	d.spp = int(d.first(tSamplesPerPixel, 1))
+	if d.spp > 16 {
+		return fmt.Errorf("tiff: %d canaux par pixel non supportés", d.spp)
+	}
+	d.bits = d.first(tBitsPerSample, 1)
+	for _, b := range d.tags[tBitsPerSample] {
+		if b != d.bits {
+			return errors.New("tiff: profondeurs différentes par canal non supportées")
+		}
+	}
+	d.photometric = d.first(tPhotometric, pBlackIsZero)
+	d.compression = Compression(d.first(tCompression, uint(Uncompressed)))
+	d.predictor = d.first(tPredictor, 1)
+
+	if d.first(tPlanarConfig, 1) != 1 {
+		return errors.New("tiff: configuration planaire séparée non supportée")
+	}
+
+	switch d.compression {
+	case Uncompressed, LZW, Deflate, deflateOld, PackBits:
+	default:
+		return fmt.Errorf("tiff: compression %d non supportée", d.compression)
+	}
+
+	if d.predictor != 1 && d.predictor != 2 {
+		return fmt.Errorf("tiff: prédicteur %d non supporté", d.predictor)
+	}
+
+	switch d.photometric {
+	case pWhiteIsZero, pBlackIsZero:
+		if d.spp < 1 || d.spp > 2 {
+			return errors.New("tiff: nombre de canaux invalide pour du gris")
+		}
+		if d.bits != 1 && d.bits != 2 && d.bits != 4 && d.bits != 8 && d.bits != 16 {
+			return fmt.Errorf("tiff: %d bits par canal non supportés", d.bits)
+		}
+		if d.spp == 2 && d.bits < 8 {
+			return errors.New("tiff: gris avec alpha en moins de 8 bits non supporté")
+		}
+	case pRGB:
+		if d.spp < 3 || (d.bits != 8 && d.bits != 16) {
+			return errors.New("tiff: seul le RGB 8 ou 16 bits est supporté")
+		}
+	case pPaletted:
+		if d.spp != 1 || d.bits > 8 {
+			return errors.New("tiff: palette invalide")
+		}
+		cmap := d.tags[tColorMap]
+		n := 1 << d.bits
+		if len(cmap) != 3*n {
+			return errors.New("tiff: table de couleurs invalide")
+		}
+		d.palette = make(color.Palette, n)
+		for i := 0; i < n; i++ {
+			d.palette[i] = color.RGBA64{uint16(cmap[i]), uint16(cmap[i+n]), uint16(cmap[i+2*n]), 0xffff}
+		}
+	default:
+		return fmt.Errorf("tiff: interprétation photométrique %d non supportée", d.photometric)
+	}
+
+	// Le premier échantillon supplémentaire est l'alpha s'il est déclaré ainsi
+	if d.spp == 2 || d.spp >= 4 {
+		d.alpha = d.first(tExtraSamples, extraUnspecified)
+		if d.alpha == extraUnspecified && d.spp == 4 {
+			d.alpha = extraUnassociated
+		}
+	}
+	return nil
+}
+
+func (d *decoder) colorModel() color.Model {
+	wide := d.bits == 16
+	switch {
+	case d.photometric == pPaletted:
+		return d.palette
+	case d.alpha == extraAssociated && wide:
+		return color.RGBA64Model
+	case d.alpha == extraAssociated:
+		return color.RGBAModel
+	case d.alpha == extraUnassociated && wide:
+		return color.NRGBA64Model
+	case d.alpha == extraUnassociated:
+		return color.NRGBAModel
+	case d.photometric == pRGB && wide:
+		return color.RGBA64Model
+	case d.photometric == pRGB:
+		return color.RGBAModel
+	case wide:
+		return color.Gray16Model
+	}
+	return color.GrayModel
+}
+
+func (d *decoder) newImage() image.Image {
+	r := image.Rect(0, 0, d.width, d.height)
+	switch d.colorModel() {
+	case color.RGBA64Model:
+		return image.NewRGBA64(r)
+	case color.RGBAModel:
+		return image.NewRGBA(r)
+	case color.NRGBA64Model:
+		return image.NewNRGBA64(r)
+	case color.NRGBAModel:
+		return image.NewNRGBA(r)
+	case color.Gray16Model:
+		return image.NewGray16(r)
+	case color.GrayModel:
+		return image.NewGray(r)
+	}
+	return image.NewPaletted(r, d.palette)
+}
+
+// block est une bande ou une tuile de l'image
+type block struct {
+	x, y, w, h int
+	offset     uint
+	count      uint
+}
+
+func (d *decoder) blocks() ([]block, error) {
+	var blocks []block
+	if _, ok := d.tags[tTileWidth]; ok {
+		w := int(d.first(tTileWidth, 0))
+		h := int(d.first(tTileLength, 0))
+		offsets, counts := d.tags[tTileOffsets], d.tags[tTileByteCounts]
+		if w <= 0 || h <= 0 || uint64(w)*uint64(h) > maxPixels {
+			return nil, errors.New("tiff: taille de tuile invalide")
+		}
+		across := (d.width + w - 1) / w
+		down := (d.height + h - 1) / h
+		if len(offsets) < across*down || len(counts) < across*down {
+			return nil, errors.New("tiff: tuiles manquantes")
+		}
+		for ty := 0; ty < down; ty++ {
+			for tx := 0; tx < across; tx++ {
+				i := ty*across + tx
+				blocks = append(blocks, block{tx * w, ty * h, w, h, offsets[i], counts[i]})
+			}
+		}
+		return blocks, nil
+	}
+
+	rps := int(d.first(tRowsPerStrip, uint(d.height)))
+	if rps <= 0 || rps > d.height {
+		rps = d.height
+	}
+	offsets, counts := d.tags[tStripOffsets], d.tags[tStripByteCounts]
+	n := (d.height + rps - 1) / rps
+	if len(offsets) < n {
+		return nil, errors.New("tiff: bandes manquantes")
+	}
+	for i := 0; i < n; i++ {
+		h := rps
+		if (i+1)*rps > d.height {
+			h = d.height - i*rps
+		}
+		var count uint
+		if i < len(counts) {
+			count = counts[i]
+		} else if d.compression == Uncompressed {
+			count = uint(d.rowBytes(d.width) * h)
+		}
+		blocks = append(blocks, block{0, i * rps, d.width, h, offsets[i], count})
+	}
+	return blocks, nil
+}
+
+func (d *decoder) rowBytes(w int) int {
+	return (w*d.spp*int(d.bits) + 7) / 8
+}
+
+func (d *decoder) decode() (image.Image, error) {
+	blocks, err := d.blocks()
+	if err != nil {
+		return nil, err
+	}
+	img := d.newImage()
+
+	for _, b := range blocks {
+		if uint64(b.offset)+uint64(b.count) > uint64(len(d.data)) {
+			return nil, errors.New("tiff: bande hors du fichier")
+		}
+		raw := d.data[b.offset : b.offset+b.count]
+		rb := d.rowBytes(b.w)
+		if uint64(rb)*uint64(b.h) > maxPixels*8 {
+			return nil, errors.New("tiff: bande trop grande")
+		}
+		size := rb * b.h
+
+		buf, err := d.decompress(raw, size)
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) < size {
+			// Bande tronquée: on complète par des zéros
+			buf = append(buf, make([]byte, size-len(buf))...)
+		}
+
+		for row := 0; row < b.h; row++ {
+			line := buf[row*rb : (row+1)*rb]
+			if d.predictor == 2 {
+				d.undoPredictor(line)
+			}
+			y := b.y + row
+			if y >= d.height {
+				break
+			}
+			for col := 0; col < b.w && b.x+col < d.width; col++ {
+				d.setPixel(img, b.x+col, y, line, col)
+			}
+		}
+	}
+	return img, nil
+}
+
+func (d *decoder) decompress(raw []byte, size int) ([]byte, error) {
+	switch d.compression {
+	case Uncompressed:
+		return raw, nil
+	case PackBits:
+		return unpackBits(raw, size)
+	case LZW:
+		return lzwDecode(raw, size)
+	case Deflate, deflateOld:
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("tiff: flux deflate invalide: %v", err)
+		}
+		defer zr.Close()
+		buf := make([]byte, size)
+		n, err := io.ReadFull(zr, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("tiff: flux deflate invalide: %v", err)
+		}
+		return buf[:n], nil
+	}
+	return nil, fmt.Errorf("tiff: compression %d non supportée", d.compression)
+}
+
+// undoPredictor annule la différenciation horizontale (prédicteur 2)
+func (d *decoder) undoPredictor(line []byte) {
+	switch d.bits {
+	case 8:
+		for i := d.spp; i < len(line); i++ {
+			line[i] += line[i-d.spp]
+		}
+	case 16:
+		for i := 2 * d.spp; i+1 < len(line); i += 2 {
+			v := d.bo.Uint16(line[i:]) + d.bo.Uint16(line[i-2*d.spp:])
+			d.bo.PutUint16(line[i:], v)
+		}
+	}
+}
+
+// sample renvoie l'échantillon i d'une ligne, quelle que soit sa profondeur
+func (d *decoder) sample(line []byte, i int) uint32 {
+	switch d.bits {
+	case 8:
+		return uint32(line[i])
+	case 16:
+		return uint32(d.bo.Uint16(line[2*i:]))
+	}
+	bit := i * int(d.bits)
+	shift := 8 - uint(bit%8) - d.bits
+	return uint32(line[bit/8]>>shift) & (1<<d.bits - 1)
+}
+
+func (d *decoder) setPixel(img image.Image, x, y int, line []byte, col int) {
+	base := col * d.spp
+	s := func(i int) uint32 { return d.sample(line, base+i) }
+
+	switch m := img.(type) {
+	case *image.Paletted:
+		m.SetColorIndex(x, y, uint8(s(0)))
+	case *image.Gray:
+		v := s(0) * 255 / (1<<d.bits - 1)
+		if d.photometric == pWhiteIsZero {
+			v = 255 - v
+		}
+		m.Pix[y*m.Stride+x] = uint8(v)
+	case *image.Gray16:
+		v := uint16(s(0))
+		if d.photometric == pWhiteIsZero {
+			v = 0xffff - v
+		}
+		m.SetGray16(x, y, color.Gray16{v})
+	case *image.RGBA:
+		if d.spp == 2 {
+			m.SetRGBA(x, y, color.RGBA{uint8(s(0)), uint8(s(0)), uint8(s(0)), uint8(s(1))})
+			return
+		}
+		a := uint32(255)
+		if d.alpha != 0 {
+			a = s(3)
+		}
+		m.SetRGBA(x, y, color.RGBA{uint8(s(0)), uint8(s(1)), uint8(s(2)), uint8(a)})
+	case *image.RGBA64:
+		if d.spp == 2 {
+			m.SetRGBA64(x, y, color.RGBA64{uint16(s(0)), uint16(s(0)), uint16(s(0)), uint16(s(1))})
+			return
+		}
+		a := uint32(0xffff)
+		if d.alpha != 0 {
+			a = s(3)
+		}
+		m.SetRGBA64(x, y, color.RGBA64{uint16(s(0)), uint16(s(1)), uint16(s(2)), uint16(a)})
+	case *image.NRGBA:
+		if d.spp == 2 {
+			m.SetNRGBA(x, y, color.NRGBA{uint8(s(0)), uint8(s(0)), uint8(s(0)), uint8(s(1))})
+			return
+		}
+		m.SetNRGBA(x, y, color.NRGBA{uint8(s(0)), uint8(s(1)), uint8(s(2)), uint8(s(3))})
+	case *image.NRGBA64:
+		if d.spp == 2 {
+			m.SetNRGBA64(x, y, color.NRGBA64{uint16(s(0)), uint16(s(0)), uint16(s(0)), uint16(s(1))})
+			return
+		}
+		m.SetNRGBA64(x, y, color.NRGBA64{uint16(s(0)), uint16(s(1)), uint16(s(2)), uint16(s(3))})
+	}
+}
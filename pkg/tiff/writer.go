@@ -0,0 +1,234 @@
+package tiff
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// Options contrôle l'encodage TIFF
+type Options struct {
+	Compression Compression
+}
+
+// Encode écrit img en TIFF petit-boutiste, une seule page. La profondeur
+// 16 bits est conservée pour les images Gray16, RGBA64 et NRGBA64.
+func Encode(w io.Writer, img image.Image, o *Options) error {
+	comp := Uncompressed
+	if o != nil && o.Compression != 0 {
+		comp = o.Compression
+	}
+	switch comp {
+	case Uncompressed, LZW, Deflate, PackBits:
+	default:
+		return fmt.Errorf("tiff: compression %d non supportée", comp)
+	}
+
+	b := img.Bounds()
+	if b.Empty() {
+		return errors.New("tiff: image vide")
+	}
+
+	// Choix de la représentation selon le type source
+	spp, photometric, bits := 3, uint16(pRGB), 8
+	switch img.(type) {
+	case *image.Gray:
+		spp, photometric = 1, pBlackIsZero
+	case *image.Gray16:
+		spp, photometric, bits = 1, pBlackIsZero, 16
+	case *image.RGBA64, *image.NRGBA64:
+		bits = 16
+	}
+	if spp == 3 {
+		if op, ok := img.(interface{ Opaque() bool }); !ok || !op.Opaque() {
+			spp = 4
+		}
+	}
+
+	// Pixels bruts, ligne par ligne
+	rowBytes := b.Dx() * spp * bits / 8
+	rps := 8192 / rowBytes
+	if rps < 1 {
+		rps = 1
+	}
+	if rps > b.Dy() {
+		rps = b.Dy()
+	}
+
+	var strips [][]byte
+	raw := make([]byte, 0, rps*rowBytes)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		raw = appendRow(raw, img, y, spp, bits)
+		if (y-b.Min.Y+1)%rps == 0 || y == b.Max.Y-1 {
+			data, err := compress(raw, rowBytes, comp)
+			if err != nil {
+				return err
+			}
+			strips = append(strips, data)
+			raw = make([]byte, 0, rps*rowBytes)
+		}
+	}
+
+	// Disposition: en-tête, bandes, puis IFD et ses valeurs déportées
+	bo := binary.LittleEndian
+	offset := uint32(8)
+	offsets := make([]uint32, len(strips))
+	counts := make([]uint32, len(strips))
+	for i, s := range strips {
+		offsets[i] = offset
+		counts[i] = uint32(len(s))
+		offset += uint32(len(s))
+		// Les valeurs TIFF sont alignées sur des mots
+		if offset%2 == 1 {
+			offset++
+		}
+	}
+	ifdOffset := offset
+
+	bps := make([]uint32, spp)
+	for i := range bps {
+		bps[i] = uint32(bits)
+	}
+	entries := []ifdEntry{
+		{tImageWidth, dtLong, []uint32{uint32(b.Dx())}},
+		{tImageLength, dtLong, []uint32{uint32(b.Dy())}},
+		{tBitsPerSample, dtShort, bps},
+		{tCompression, dtShort, []uint32{uint32(comp)}},
+		{tPhotometric, dtShort, []uint32{uint32(photometric)}},
+		{tStripOffsets, dtLong, offsets},
+		{tSamplesPerPixel, dtShort, []uint32{uint32(spp)}},
+		{tRowsPerStrip, dtLong, []uint32{uint32(rps)}},
+		{tStripByteCounts, dtLong, counts},
+		{tXResolution, dtRational, []uint32{72, 1}},
+		{tYResolution, dtRational, []uint32{72, 1}},
+		{tPlanarConfig, dtShort, []uint32{1}},
+		{tResolutionUnit, dtShort, []uint32{2}},
+	}
+	if spp == 4 {
+		entries = append(entries, ifdEntry{tExtraSamples, dtShort, []uint32{extraUnassociated}})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	var buf bytes.Buffer
+	buf.WriteString("II*\x00")
+	binary.Write(&buf, bo, ifdOffset)
+	for _, s := range strips {
+		buf.Write(s)
+		if buf.Len()%2 == 1 {
+			buf.WriteByte(0)
+		}
+	}
+
+	// Les valeurs de plus de 4 octets suivent l'IFD
+	extra := ifdOffset + 2 + uint32(len(entries))*12 + 4
+	var values bytes.Buffer
+	binary.Write(&buf, bo, uint16(len(entries)))
+	for _, e := range entries {
+		data := e.bytes(bo)
+		binary.Write(&buf, bo, e.tag)
+		binary.Write(&buf, bo, e.typ)
+		binary.Write(&buf, bo, e.count())
+		if len(data) <= 4 {
+			field := make([]byte, 4)
+			copy(field, data)
+			buf.Write(field)
+			continue
+		}
+		binary.Write(&buf, bo, extra+uint32(values.Len()))
+		values.Write(data)
+		if values.Len()%2 == 1 {
+			values.WriteByte(0)
+		}
+	}
+	binary.Write(&buf, bo, uint32(0))
+	buf.Write(values.Bytes())
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type ifdEntry struct {
+	tag    uint16
+	typ    uint16
+	values []uint32
+}
+
+func (e ifdEntry) count() uint32 {
+	if e.typ == dtRational {
+		return uint32(len(e.values) / 2)
+	}
+	return uint32(len(e.values))
+}
+
+func (e ifdEntry) bytes(bo binary.AppendByteOrder) []byte {
+	var out []byte
+	for _, v := range e.values {
+		if e.typ == dtShort {
+			out = bo.AppendUint16(out, uint16(v))
+		} else {
+			out = bo.AppendUint32(out, v)
+		}
+	}
+	return out
+}
+
+// appendRow ajoute les échantillons d'une ligne (16 bits en petit-boutiste)
+func appendRow(dst []byte, img image.Image, y, spp, bits int) []byte {
+	b := img.Bounds()
+	put := func(v uint16) {
+		if bits == 16 {
+			dst = append(dst, byte(v), byte(v>>8))
+		} else {
+			dst = append(dst, byte(v>>8))
+		}
+	}
+	for x := b.Min.X; x < b.Max.X; x++ {
+		c := img.At(x, y)
+		switch spp {
+		case 1:
+			put(color.Gray16Model.Convert(c).(color.Gray16).Y)
+		case 3:
+			r, g, bl, _ := c.RGBA()
+			put(uint16(r))
+			put(uint16(g))
+			put(uint16(bl))
+		default:
+			n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+			put(n.R)
+			put(n.G)
+			put(n.B)
+			put(n.A)
+		}
+	}
+	return dst
+}
+
+func compress(raw []byte, rowBytes int, comp Compression) ([]byte, error) {
+	switch comp {
+	case LZW:
+		return lzwEncode(raw), nil
+	case PackBits:
+		var out []byte
+		for i := 0; i < len(raw); i += rowBytes {
+			out = packBits(out, raw[i:i+rowBytes])
+		}
+		return out, nil
+	case Deflate:
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return append([]byte(nil), raw...), nil
+}
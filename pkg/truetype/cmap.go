@@ -0,0 +1,125 @@
+package truetype
+
+import "errors"
+
+// parseCmap choisit une sous-table Unicode (format 12 de préférence, sinon
+// format 4) et renvoie la fonction de correspondance
+func parseCmap(data []byte) (func(rune) GlyphIndex, error) {
+	if len(data) < 4 {
+		return nil, errFormat
+	}
+	n := int(be.Uint16(data[2:]))
+	var best []byte
+	bestScore := 0
+	for i := 0; i < n && 4+8*(i+1) <= len(data); i++ {
+		rec := data[4+8*i:]
+		platform, encoding := be.Uint16(rec), be.Uint16(rec[2:])
+		off := int(be.Uint32(rec[4:]))
+		if off+2 > len(data) {
+			continue
+		}
+		unicode := platform == 0 || platform == 3 && (encoding == 1 || encoding == 10)
+		if !unicode {
+			continue
+		}
+		score := 0
+		switch be.Uint16(data[off:]) {
+		case 4:
+			score = 1
+		case 12:
+			score = 2
+		}
+		if score > bestScore {
+			best, bestScore = data[off:], score
+		}
+	}
+
+	switch bestScore {
+	case 1:
+		return parseCmap4(best)
+	case 2:
+		return parseCmap12(best)
+	}
+	return nil, errors.New("truetype: aucune table cmap Unicode gérée")
+}
+
+// parseCmap4 lit le format 4: segments de caractères BMP
+func parseCmap4(data []byte) (func(rune) GlyphIndex, error) {
+	if len(data) < 14 {
+		return nil, errFormat
+	}
+	segs := int(be.Uint16(data[6:])) / 2
+	ends := 14
+	starts := ends + 2*segs + 2
+	deltas := starts + 2*segs
+	ranges := deltas + 2*segs
+	if ranges+2*segs > len(data) {
+		return nil, errFormat
+	}
+	return func(r rune) GlyphIndex {
+		if r < 0 || r > 0xffff {
+			return 0
+		}
+		c := uint16(r)
+		// Recherche dichotomique du premier segment finissant après c
+		lo, hi := 0, segs
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if be.Uint16(data[ends+2*mid:]) < c {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		if lo == segs || be.Uint16(data[starts+2*lo:]) > c {
+			return 0
+		}
+		start := be.Uint16(data[starts+2*lo:])
+		delta := be.Uint16(data[deltas+2*lo:])
+		rangeOff := int(be.Uint16(data[ranges+2*lo:]))
+		if rangeOff == 0 {
+			return GlyphIndex(c + delta)
+		}
+		// Décalage relatif à l'emplacement de idRangeOffset lui-même
+		pos := ranges + 2*lo + rangeOff + 2*int(c-start)
+		if pos+2 > len(data) {
+			return 0
+		}
+		if g := be.Uint16(data[pos:]); g != 0 {
+			return GlyphIndex(g + delta)
+		}
+		return 0
+	}, nil
+}
+
+// parseCmap12 lit le format 12: groupes couvrant tout Unicode
+func parseCmap12(data []byte) (func(rune) GlyphIndex, error) {
+	if len(data) < 16 {
+		return nil, errFormat
+	}
+	n := int(be.Uint32(data[12:]))
+	if 16+12*n > len(data) {
+		return nil, errFormat
+	}
+	return func(r rune) GlyphIndex {
+		c := uint32(r)
+		lo, hi := 0, n
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if be.Uint32(data[16+12*mid+4:]) < c {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		if lo == n {
+			return 0
+		}
+		group := data[16+12*lo:]
+		start := be.Uint32(group)
+		if c < start {
+			return 0
+		}
+		return GlyphIndex(be.Uint32(group[8:]) + c - start)
+	}, nil
+}
@@ -0,0 +1,201 @@
+// Package truetype lit les polices TrueType (.ttf, .ttc): correspondance
+// caractères/glyphes, contours quadratiques, métriques horizontales et
+// crénage. Le rendu des contours est laissé à l'appelant.
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf16"
+)
+
+var (
+	errFormat = errors.New("truetype: fichier de police invalide")
+	be        = binary.BigEndian
+)
+
+// GlyphIndex est le numéro d'un glyphe dans la police; 0 est le glyphe
+// de remplacement (.notdef)
+type GlyphIndex uint16
+
+// Font est une police TrueType décodée
+type Font struct {
+	// Name est le nom complet de la police (table name)
+	Name string
+
+	unitsPerEm int
+	ascent     int
+	descent    int
+	lineGap    int
+	numGlyphs  int
+	longLoca   bool
+	advances   []uint16
+	loca, glyf []byte
+	cmap       func(rune) GlyphIndex
+	kern       map[uint32]int16
+}
+
+// Open lit et décode une police depuis le disque
+func Open(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse décode une police TrueType; pour une collection (.ttc), la
+// première police est retenue
+func Parse(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, errFormat
+	}
+	offset := 0
+	switch string(data[:4]) {
+	case "ttcf":
+		if len(data) < 16 {
+			return nil, errFormat
+		}
+		offset = int(be.Uint32(data[12:]))
+	case "OTTO":
+		return nil, errors.New("truetype: polices OpenType à contours CFF non gérées")
+	}
+	tables, err := readTables(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "loca", "glyf"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("truetype: table %s absente", tag)
+		}
+	}
+
+	f := &Font{loca: tables["loca"], glyf: tables["glyf"]}
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errFormat
+	}
+	f.unitsPerEm = int(be.Uint16(head[18:]))
+	f.longLoca = be.Uint16(head[50:]) != 0
+	f.ascent = int(int16(be.Uint16(hhea[4:])))
+	f.descent = int(int16(be.Uint16(hhea[6:])))
+	f.lineGap = int(int16(be.Uint16(hhea[8:])))
+	f.numGlyphs = int(be.Uint16(maxp[4:]))
+	if f.unitsPerEm == 0 {
+		return nil, errFormat
+	}
+
+	// Avances: les glyphes au-delà de numberOfHMetrics reprennent la dernière
+	nMetrics := int(be.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if nMetrics == 0 || len(hmtx) < 4*nMetrics {
+		return nil, errFormat
+	}
+	f.advances = make([]uint16, nMetrics)
+	for i := range f.advances {
+		f.advances[i] = be.Uint16(hmtx[4*i:])
+	}
+
+	locaSize := 2
+	if f.longLoca {
+		locaSize = 4
+	}
+	if len(f.loca) < (f.numGlyphs+1)*locaSize {
+		return nil, errFormat
+	}
+
+	if f.cmap, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	f.kern = parseKern(tables["kern"])
+	f.Name = parseName(tables["name"])
+	return f, nil
+}
+
+// readTables lit le répertoire des tables de la police commençant à offset
+func readTables(data []byte, offset int) (map[string][]byte, error) {
+	if offset+12 > len(data) {
+		return nil, errFormat
+	}
+	n := int(be.Uint16(data[offset+4:]))
+	if offset+12+16*n > len(data) {
+		return nil, errFormat
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := data[offset+12+16*i:]
+		start, length := uint64(be.Uint32(rec[8:])), uint64(be.Uint32(rec[12:]))
+		if start+length > uint64(len(data)) {
+			return nil, fmt.Errorf("truetype: table %s tronquée", rec[:4])
+		}
+		tables[string(rec[:4])] = data[start : start+length]
+	}
+	return tables, nil
+}
+
+// UnitsPerEm renvoie le nombre d'unités de la grille de dessin par cadratin
+func (f *Font) UnitsPerEm() int { return f.unitsPerEm }
+
+// NumGlyphs renvoie le nombre de glyphes de la police
+func (f *Font) NumGlyphs() int { return f.numGlyphs }
+
+// Metrics renvoie la hauteur au-dessus de la ligne de base, la profondeur
+// (négative) et l'interligne supplémentaire, en unités de police
+func (f *Font) Metrics() (ascent, descent, lineGap int) {
+	return f.ascent, f.descent, f.lineGap
+}
+
+// Index renvoie le glyphe du caractère, 0 s'il est absent
+func (f *Font) Index(r rune) GlyphIndex {
+	return f.cmap(r)
+}
+
+// Advance renvoie l'avance horizontale du glyphe en unités de police
+func (f *Font) Advance(g GlyphIndex) int {
+	if int(g) < len(f.advances) {
+		return int(f.advances[g])
+	}
+	return int(f.advances[len(f.advances)-1])
+}
+
+// Kern renvoie l'ajustement entre deux glyphes consécutifs (table kern)
+func (f *Font) Kern(left, right GlyphIndex) int {
+	return int(f.kern[uint32(left)<<16|uint32(right)])
+}
+
+// parseName renvoie le nom complet (ID 4), à défaut le nom de famille (ID 1)
+func parseName(data []byte) string {
+	if len(data) < 6 {
+		return ""
+	}
+	count, storage := int(be.Uint16(data[2:])), int(be.Uint16(data[4:]))
+	names := map[uint16]string{}
+	for i := 0; i < count && 6+12*(i+1) <= len(data); i++ {
+		rec := data[6+12*i:]
+		platform, id := be.Uint16(rec), be.Uint16(rec[6:])
+		length, off := int(be.Uint16(rec[8:])), int(be.Uint16(rec[10:]))
+		if storage+off+length > len(data) || (id != 1 && id != 4) {
+			continue
+		}
+		raw := data[storage+off : storage+off+length]
+		switch platform {
+		case 0, 3:
+			// UTF-16 gros-boutiste, prioritaire
+			u := make([]uint16, len(raw)/2)
+			for j := range u {
+				u[j] = be.Uint16(raw[2*j:])
+			}
+			names[id] = string(utf16.Decode(u))
+		case 1:
+			if _, ok := names[id]; !ok {
+				names[id] = string(raw)
+			}
+		}
+	}
+	if names[4] != "" {
+		return names[4]
+	}
+	return names[1]
+}
@@ -0,0 +1,227 @@
+package truetype
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Profondeur maximale des glyphes composites imbriqués
+const maxCompositeDepth = 8
+
+// Indicateurs des points d'un glyphe simple
+const (
+	flagOnCurve = 1 << iota
+	flagXShort
+	flagYShort
+	flagRepeat
+	flagXSame
+	flagYSame
+)
+
+// Indicateurs des composants d'un glyphe composite
+const (
+	compArgWords = 0x0001
+	compArgsXY   = 0x0002
+	compScale    = 0x0008
+	compMore     = 0x0020
+	compXYScale  = 0x0040
+	compTwoByTwo = 0x0080
+)
+
+// Point est un point de contour en unités de police, l'axe Y vers le haut.
+// Deux points hors courbe consécutifs impliquent un point sur la courbe à
+// mi-chemin (B-splines quadratiques)
+type Point struct {
+	X, Y    float64
+	OnCurve bool
+}
+
+// Glyph renvoie les contours fermés du glyphe; un glyphe vide (espace)
+// n'a aucun contour
+func (f *Font) Glyph(g GlyphIndex) ([][]Point, error) {
+	return f.glyph(g, 0)
+}
+
+func (f *Font) glyph(g GlyphIndex, depth int) ([][]Point, error) {
+	if int(g) >= f.numGlyphs {
+		return nil, fmt.Errorf("truetype: glyphe %d hors limites", g)
+	}
+	var start, end int
+	if f.longLoca {
+		start, end = int(be.Uint32(f.loca[4*g:])), int(be.Uint32(f.loca[4*g+4:]))
+	} else {
+		start, end = 2*int(be.Uint16(f.loca[2*g:])), 2*int(be.Uint16(f.loca[2*g+2:]))
+	}
+	if start == end {
+		return nil, nil
+	}
+	if start > end || end > len(f.glyf) || end-start < 10 {
+		return nil, errFormat
+	}
+	data := f.glyf[start:end]
+	n := int(int16(be.Uint16(data)))
+	if n >= 0 {
+		return parseSimple(data, n)
+	}
+	if depth >= maxCompositeDepth {
+		return nil, errors.New("truetype: glyphes composites trop imbriqués")
+	}
+	return f.parseComposite(data, depth)
+}
+
+func parseSimple(data []byte, n int) ([][]Point, error) {
+	pos := 10
+	if pos+2*n+2 > len(data) {
+		return nil, errFormat
+	}
+	ends := make([]int, n)
+	for i := range ends {
+		ends[i] = int(be.Uint16(data[pos+2*i:]))
+	}
+	pos += 2 * n
+	if n == 0 {
+		return nil, nil
+	}
+	count := ends[n-1] + 1
+	pos += 2 + int(be.Uint16(data[pos:])) // instructions ignorées
+
+	// Indicateurs, avec répétitions
+	flags := make([]byte, 0, count)
+	for len(flags) < count {
+		if pos >= len(data) {
+			return nil, errFormat
+		}
+		flag := data[pos]
+		pos++
+		flags = append(flags, flag)
+		if flag&flagRepeat != 0 {
+			if pos >= len(data) {
+				return nil, errFormat
+			}
+			for r := int(data[pos]); r > 0 && len(flags) < count; r-- {
+				flags = append(flags, flag)
+			}
+			pos++
+		}
+	}
+
+	// Coordonnées relatives: X puis Y
+	points := make([]Point, count)
+	readAxis := func(short, same byte, set func(i int, v float64)) error {
+		v := 0
+		for i, flag := range flags {
+			switch {
+			case flag&short != 0:
+				if pos >= len(data) {
+					return errFormat
+				}
+				d := int(data[pos])
+				pos++
+				if flag&same == 0 {
+					d = -d
+				}
+				v += d
+			case flag&same == 0:
+				if pos+2 > len(data) {
+					return errFormat
+				}
+				v += int(int16(be.Uint16(data[pos:])))
+				pos += 2
+			}
+			set(i, float64(v))
+		}
+		return nil
+	}
+	if err := readAxis(flagXShort, flagXSame, func(i int, v float64) { points[i].X = v }); err != nil {
+		return nil, err
+	}
+	if err := readAxis(flagYShort, flagYSame, func(i int, v float64) { points[i].Y = v }); err != nil {
+		return nil, err
+	}
+	for i, flag := range flags {
+		points[i].OnCurve = flag&flagOnCurve != 0
+	}
+
+	contours := make([][]Point, 0, n)
+	first := 0
+	for _, e := range ends {
+		if e < first || e >= count {
+			return nil, errFormat
+		}
+		contours = append(contours, points[first:e+1])
+		first = e + 1
+	}
+	return contours, nil
+}
+
+// parseComposite assemble les composants transformés d'un glyphe composite
+func (f *Font) parseComposite(data []byte, depth int) ([][]Point, error) {
+	var contours [][]Point
+	pos := 10
+	for {
+		if pos+4 > len(data) {
+			return nil, errFormat
+		}
+		flags := be.Uint16(data[pos:])
+		component := GlyphIndex(be.Uint16(data[pos+2:]))
+		pos += 4
+
+		var dx, dy float64
+		if flags&compArgWords != 0 {
+			if pos+4 > len(data) {
+				return nil, errFormat
+			}
+			dx, dy = float64(int16(be.Uint16(data[pos:]))), float64(int16(be.Uint16(data[pos+2:])))
+			pos += 4
+		} else {
+			if pos+2 > len(data) {
+				return nil, errFormat
+			}
+			dx, dy = float64(int8(data[pos])), float64(int8(data[pos+1]))
+			pos += 2
+		}
+		// Alignement par numéros de points non géré: composant non décalé
+		if flags&compArgsXY == 0 {
+			dx, dy = 0, 0
+		}
+
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		f2dot14 := func(i int) float64 { return float64(int16(be.Uint16(data[pos+2*i:]))) / 16384 }
+		switch {
+		case flags&compScale != 0:
+			if pos+2 > len(data) {
+				return nil, errFormat
+			}
+			a = f2dot14(0)
+			d = a
+			pos += 2
+		case flags&compXYScale != 0:
+			if pos+4 > len(data) {
+				return nil, errFormat
+			}
+			a, d = f2dot14(0), f2dot14(1)
+			pos += 4
+		case flags&compTwoByTwo != 0:
+			if pos+8 > len(data) {
+				return nil, errFormat
+			}
+			a, b, c, d = f2dot14(0), f2dot14(1), f2dot14(2), f2dot14(3)
+			pos += 8
+		}
+
+		sub, err := f.glyph(component, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, contour := range sub {
+			out := make([]Point, len(contour))
+			for i, p := range contour {
+				out[i] = Point{a*p.X + c*p.Y + dx, b*p.X + d*p.Y + dy, p.OnCurve}
+			}
+			contours = append(contours, out)
+		}
+		if flags&compMore == 0 {
+			return contours, nil
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package truetype
+
+// parseKern lit les paires de la table kern (format 0, crénage horizontal);
+// les ajustements de plusieurs sous-tables s'additionnent
+func parseKern(data []byte) map[uint32]int16 {
+	if len(data) < 4 || be.Uint16(data) != 0 {
+		return nil
+	}
+	pairs := map[uint32]int16{}
+	n := int(be.Uint16(data[2:]))
+	off := 4
+	for i := 0; i < n && off+6 <= len(data); i++ {
+		length := int(be.Uint16(data[off+2:]))
+		coverage := be.Uint16(data[off+4:])
+		sub := data[off:]
+		if length < 6 || off+length > len(data) {
+			break
+		}
+		sub = sub[:length]
+		off += length
+
+		// Format 0 horizontal, ni minimum ni perpendiculaire
+		if coverage>>8 != 0 || coverage&0x7 != 1 || len(sub) < 14 {
+			continue
+		}
+		count := int(be.Uint16(sub[6:]))
+		for j := 0; j < count && 14+6*(j+1) <= len(sub); j++ {
+			p := sub[14+6*j:]
+			key := be.Uint32(p)
+			pairs[key] += int16(be.Uint16(p[4:]))
+		}
+	}
+	return pairs
+}